@@ -0,0 +1,115 @@
+package going
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AuthUserKey is the Context key BasicAuth stores the user name under
+	AuthUserKey = "user"
+	// AuthPrincipalKey is the Context key BearerAuth stores the validated principal under
+	AuthPrincipalKey = "going/principal"
+)
+
+// Accounts maps user names to passwords for BasicAuth
+type Accounts map[string]string
+
+type authPair struct {
+	value string
+	user  string
+}
+
+func authorizationHeader(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// searchCredential compares against every account so the timing does not reveal which user exists
+func searchCredential(pairs []authPair, authValue string) (user string, found bool) {
+	if authValue == "" {
+		return "", false
+	}
+	for _, pair := range pairs {
+		if subtle.ConstantTimeCompare([]byte(pair.value), []byte(authValue)) == 1 && !found {
+			user, found = pair.user, true
+		}
+	}
+	return
+}
+
+// BasicAuth returns a Basic HTTP Authorization middleware
+func BasicAuth(accounts Accounts) HandlerFunc {
+	return BasicAuthForRealm(accounts, "")
+}
+
+// BasicAuthForRealm returns a Basic HTTP Authorization middleware for realm,
+// "Authorization Required" is used when realm is empty
+func BasicAuthForRealm(accounts Accounts, realm string) HandlerFunc {
+	if realm == "" {
+		realm = "Authorization Required"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm)
+	if len(accounts) == 0 {
+		panic("going: empty list of authorized credentials")
+	}
+	pairs := make([]authPair, 0, len(accounts))
+	for user, password := range accounts {
+		if user == "" {
+			panic("going: user can not be empty")
+		}
+		pairs = append(pairs, authPair{value: authorizationHeader(user, password), user: user})
+	}
+	return func(c *Context) {
+		user, found := searchCredential(pairs, c.Req.Header.Get("Authorization"))
+		if !found {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.Fail(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+// BearerAuth returns a middleware that passes the bearer token to validator
+// and stores the returned principal on the Context
+func BearerAuth(validator func(token string) (principal any, err error)) HandlerFunc {
+	return BearerAuthForRealm("", validator)
+}
+
+// BearerAuthForRealm is BearerAuth announcing realm in its RFC 6750 challenge
+func BearerAuthForRealm(realm string, validator func(token string) (principal any, err error)) HandlerFunc {
+	challenge := "Bearer"
+	if realm != "" {
+		challenge += " realm=" + strconv.Quote(realm) + ","
+	}
+	return func(c *Context) {
+		token, ok := bearerToken(c.Req.Header.Get("Authorization"))
+		if !ok {
+			c.SetHeader("WWW-Authenticate", strings.TrimSuffix(challenge, ","))
+			c.Fail(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		principal, err := validator(token)
+		if err != nil {
+			c.SetHeader("WWW-Authenticate", challenge+` error="invalid_token", error_description=`+strconv.Quote(err.Error()))
+			c.Fail(http.StatusUnauthorized, "invalid token")
+			return
+		}
+		c.Set(AuthPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,62 @@
+package going
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	r := New()
+	r.Use(BasicAuthForRealm(Accounts{"admin": "secret"}, "admin area"))
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, c.GetString(AuthUserKey))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "admin" {
+		t.Fatalf("valid credentials should pass, got %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "wrong")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Basic realm="admin area"` {
+		t.Fatalf("invalid credentials should be challenged, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	r := New()
+	r.Use(BearerAuthForRealm("api", func(token string) (any, error) {
+		if token != "valid" {
+			return nil, errors.New("token expired")
+		}
+		return "alice", nil
+	}))
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "%v", c.MustGet(AuthPrincipalKey))
+	})
+
+	for _, tt := range []struct {
+		header, challenge string
+		code              int
+	}{
+		{"Bearer valid", "", http.StatusOK},
+		{"", `Bearer realm="api"`, http.StatusUnauthorized},
+		{"Bearer nope", `Bearer realm="api", error="invalid_token", error_description="token expired"`, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", tt.header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Header().Get("WWW-Authenticate") != tt.challenge {
+			t.Fatalf("%q: got %d %q", tt.header, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package going
+
+import (
+	"io"
+	"net/http"
+)
+
+// bodyLimitKey holds the body of the innermost BodyLimit run so far
+const bodyLimitKey = "going/bodyLimit"
+
+// BodyLimitConfig defines the config for the BodyLimit middleware
+type BodyLimitConfig struct {
+	// Limit is the maximum number of body bytes
+	Limit int64
+	// Handler answers requests over the limit, a 413 by default
+	Handler HandlerFunc
+}
+
+// BodyLimit returns a middleware rejecting request bodies larger than n bytes with 413
+func BodyLimit(n int64) HandlerFunc {
+	return BodyLimitWithConfig(BodyLimitConfig{Limit: n})
+}
+
+// BodyLimitWithConfig returns a BodyLimit middleware with config. When BodyLimit is used
+// by nested groups the innermost limit replaces the outer ones instead of adding up,
+// so an upload group can allow more than the engine wide default.
+func BodyLimitWithConfig(config BodyLimitConfig) HandlerFunc {
+	if config.Limit <= 0 {
+		panic("going: body limit must be positive")
+	}
+	if config.Handler == nil {
+		config.Handler = bodyTooLarge
+	}
+	return func(c *Context) {
+		if c.Req.Body == nil || c.Req.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		// the innermost limit replaces the outer one, whose body keeps reading
+		// through as it may be wrapped by middlewares such as Decompress
+		if outer, ok := c.Get(bodyLimitKey); ok {
+			outer.(*limitedBody).limit = -1
+		}
+		body := newLimitedBody(c.Req.Body, nil, config.Limit, config.Handler)
+		c.Set(bodyLimitKey, body)
+		c.Req.Body = body
+		c.Next()
+		if body.exceeded {
+			body.reject(c)
+		}
+	}
+}
+
+// BodyLimit limits the request bodies of the group's routes to n bytes
+func (group *RouterGroup) BodyLimit(n int64) {
+	group.Use(BodyLimit(n))
+}
+
+// checkDeclaredBody runs right before the route handler, once the innermost BodyLimit
+// is known, and rejects a body declared larger than its limit without reading it
+func checkDeclaredBody(c *Context) {
+	if c.Req.ContentLength <= 0 {
+		return
+	}
+	if value, ok := c.Get(bodyLimitKey); ok {
+		if body := value.(*limitedBody); body.limit >= 0 && c.Req.ContentLength > body.limit {
+			// the BodyLimit middleware answers once the chain returns
+			body.exceeded = true
+			c.Abort()
+		}
+	}
+}
+
+func bodyTooLarge(c *Context) {
+	c.Fail(http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+}
+
+// limitedBody caps a request body and remembers whether the handler ran into
+// the limit, so the middleware can answer 413. A negative limit is lifted.
+type limitedBody struct {
+	reader   io.Reader
+	closers  []io.Closer
+	limit    int64
+	read     int64
+	exceeded bool
+	rejected bool
+	handler  HandlerFunc
+}
+
+func newLimitedBody(r io.Reader, closer io.Closer, n int64, handler HandlerFunc) *limitedBody {
+	body := &limitedBody{reader: r, limit: n, handler: handler}
+	if rc, ok := r.(io.Closer); ok {
+		body.closers = append(body.closers, rc)
+	}
+	if closer != nil {
+		body.closers = append(body.closers, closer)
+	}
+	return body
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	limit := b.limit
+	if limit >= 0 && int64(len(p)) > limit-b.read+1 {
+		// read one byte past the limit to tell a body of exactly limit bytes apart
+		p = p[:limit-b.read+1]
+	}
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+	if limit >= 0 && b.read > limit {
+		n -= int(b.read - limit)
+		b.read = limit
+		b.exceeded = true
+		return n, &http.MaxBytesError{Limit: limit}
+	}
+	return n, err
+}
+
+// reject answers the request with the handler of the limit, unless a response
+// was already sent
+func (b *limitedBody) reject(c *Context) {
+	if b.rejected || c.Writer.Written() {
+		return
+	}
+	b.rejected = true
+	c.Abort()
+	b.handler(c)
+}
+
+func (b *limitedBody) Close() error {
+	var err error
+	for _, closer := range b.closers {
+		if cerr := closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// exceededBody returns the body of the chain starting at r that went over its limit
+func exceededBody(r io.Reader) *limitedBody {
+	for {
+		body, ok := r.(*limitedBody)
+		if !ok {
+			return nil
+		}
+		if body.exceeded {
+			return body
+		}
+		r = body.reader
+	}
+}
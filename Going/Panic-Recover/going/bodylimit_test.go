@@ -0,0 +1,101 @@
+package going
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimit(t *testing.T) {
+	r := New()
+	r.Use(BodyLimit(8))
+	echo := func(c *Context) {
+		data, err := io.ReadAll(c.Req.Body)
+		if err != nil {
+			return
+		}
+		c.Data(http.StatusOK, data)
+	}
+	r.POST("/echo", echo)
+	upload := r.Group("/upload")
+	upload.BodyLimit(32)
+	upload.POST("/file", echo)
+
+	post := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/echo", "small", false); w.Code != http.StatusOK || w.Body.String() != "small" {
+		t.Fatalf("bodies under the limit should pass, got %d", w.Code)
+	}
+	if w := post("/echo", strings.Repeat("a", 16), false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared length over the limit should be rejected, got %d", w.Code)
+	}
+	if w := post("/echo", strings.Repeat("a", 16), true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("streamed body over the limit should be rejected, got %d", w.Code)
+	}
+	if w := post("/upload/file", strings.Repeat("a", 16), true); w.Code != http.StatusOK {
+		t.Fatalf("group limit should replace the engine limit, got %d", w.Code)
+	}
+	if w := post("/upload/file", strings.Repeat("a", 16), false); w.Code != http.StatusOK || w.Body.String() != strings.Repeat("a", 16) {
+		t.Fatalf("group limit should replace the engine limit for a declared length, got %d", w.Code)
+	}
+	if w := post("/upload/file", strings.Repeat("a", 64), false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared length over the group limit should be rejected, got %d", w.Code)
+	}
+	if w := post("/upload/file", strings.Repeat("a", 64), true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("group limit should still apply, got %d", w.Code)
+	}
+
+	// the declared length is rejected before the handler runs, even when it reads nothing
+	called := false
+	r.POST("/ignore", func(c *Context) {
+		called = true
+		c.String(http.StatusOK, "ignored")
+	})
+	if w := post("/ignore", strings.Repeat("a", 16), false); w.Code != http.StatusRequestEntityTooLarge || called {
+		t.Fatalf("declared length over the limit should be rejected before the handler, got %d", w.Code)
+	}
+
+	r.POST("/form", func(c *Context) {
+		c.String(http.StatusOK, "name=%s", c.PostForm("name"))
+	})
+	req := httptest.NewRequest("POST", "/form", strings.NewReader("name="+strings.Repeat("a", 100)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("PostForm should answer a form over the limit with 413, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestBodyLimitKeepsInnerBodies(t *testing.T) {
+	r := New()
+	r.Use(BodyLimit(1<<20), Decompress())
+	upload := r.Group("/upload")
+	upload.BodyLimit(10 << 20)
+	upload.POST("/file", func(c *Context) {
+		data, err := io.ReadAll(c.Req.Body)
+		if err != nil {
+			return
+		}
+		c.Data(http.StatusOK, data)
+	})
+
+	req := httptest.NewRequest("POST", "/upload/file", gzipBody("going"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "going" {
+		t.Fatalf("the group limit should wrap the decompressed body, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,340 @@
+package going
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder compresses a response body, it is Reset and reused across responses
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFactory creates an Encoder writing to w at the given compression level
+type EncoderFactory func(w io.Writer, level int) (Encoder, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFactory{
+		"gzip": func(w io.Writer, level int) (Encoder, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+		// the HTTP deflate coding is the zlib format, refer RFC 9110 section 8.4.1.2
+		"deflate": func(w io.Writer, level int) (Encoder, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	}
+)
+
+// RegisterEncoder makes a content coding available to Compress, e.g. "br"
+func RegisterEncoder(name string, factory EncoderFactory) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(name)] = factory
+}
+
+func lookupEncoder(name string) EncoderFactory {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	return encoders[name]
+}
+
+// CompressConfig defines the config for the Compress middleware
+type CompressConfig struct {
+	// Level is passed to the encoders, 0 means flate.DefaultCompression
+	Level int
+	// MinLength is the body size below which responses are sent as is, 1024 by default
+	MinLength int
+	// Encodings in order of server preference, gzip and deflate by default
+	Encodings []string
+	// ExcludedContentTypes are content type prefixes that are already compressed
+	ExcludedContentTypes []string
+	// ExcludedPaths are request path prefixes that are never compressed
+	ExcludedPaths []string
+}
+
+var defaultExcludedContentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"application/x-7z-compressed", "application/x-rar-compressed",
+	"application/octet-stream", "application/pdf",
+}
+
+type compressor struct {
+	config CompressConfig
+	pools  map[string]*sync.Pool
+}
+
+// Compress returns a middleware compressing response bodies with the best
+// encoding the client accepts, while keeping http.Flusher working for streams
+func Compress(config ...CompressConfig) HandlerFunc {
+	var cfg CompressConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Level == 0 {
+		cfg.Level = flate.DefaultCompression
+	}
+	if cfg.MinLength == 0 {
+		cfg.MinLength = 1024
+	}
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = []string{"gzip", "deflate"}
+	}
+	if cfg.ExcludedContentTypes == nil {
+		cfg.ExcludedContentTypes = defaultExcludedContentTypes
+	}
+
+	cp := &compressor{pools: make(map[string]*sync.Pool)}
+	encodings := make([]string, len(cfg.Encodings))
+	for i, name := range cfg.Encodings {
+		name = strings.ToLower(name)
+		encodings[i] = name
+		factory := lookupEncoder(name)
+		if factory == nil {
+			panic("going: no encoder registered for " + name)
+		}
+		// fail at startup rather than on the first request
+		if _, err := factory(io.Discard, cfg.Level); err != nil {
+			panic(err)
+		}
+		level := cfg.Level
+		cp.pools[name] = &sync.Pool{New: func() any {
+			enc, _ := factory(io.Discard, level)
+			return enc
+		}}
+	}
+	cfg.Encodings = encodings
+	cp.config = cfg
+
+	return func(c *Context) {
+		if cp.excludedPath(c.Path) || c.Method == http.MethodHead || c.Req.Header.Get("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			cp:             cp,
+			encoding:       cp.negotiate(c.Req.Header.Get("Accept-Encoding")),
+		}
+		c.Writer = w
+		panicked := true
+		defer func() {
+			c.Writer = w.ResponseWriter
+			w.finish(panicked)
+		}()
+		c.Next()
+		panicked = false
+	}
+}
+
+func (cp *compressor) excludedPath(path string) bool {
+	for _, prefix := range cp.config.ExcludedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cp *compressor) excludedContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range cp.config.ExcludedContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate picks the accepted encoding with the highest q-value,
+// ties are broken by the server preference order
+func (cp *compressor) negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qualities := parseAcceptEncoding(acceptEncoding)
+	candidates := make([]string, 0, len(cp.config.Encodings))
+	for _, name := range cp.config.Encodings {
+		q, ok := qualities[name]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > 0 {
+			qualities[name] = q
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return qualities[candidates[i]] > qualities[candidates[j]]
+	})
+	return candidates[0]
+}
+
+// parseAcceptEncoding maps the lower-cased codings of an Accept-Encoding header to their q-value
+func parseAcceptEncoding(acceptEncoding string) map[string]float64 {
+	qualities := make(map[string]float64)
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if parsed, err := strconv.ParseFloat(params[2:], 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	return qualities
+}
+
+// compressWriter buffers the start of the body until it knows whether compressing is worth it
+type compressWriter struct {
+	ResponseWriter
+	cp       *compressor
+	encoding string
+	enc      Encoder
+	buf      []byte
+	size     int
+	decided  bool
+}
+
+// candidate reports whether the response could be compressed for some client
+func (w *compressWriter) candidate() bool {
+	status := w.ResponseWriter.Status()
+	if status < http.StatusOK || status == http.StatusNoContent ||
+		status == http.StatusPartialContent || status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	return header.Get("Content-Encoding") == "" && !w.cp.excludedContentType(header.Get("Content-Type"))
+}
+
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// sniff now, net/http would otherwise sniff the compressed bytes
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if w.candidate() {
+		addVary(header, "Accept-Encoding")
+	} else {
+		compress = false
+	}
+	buf := w.buf
+	w.buf = nil
+	if !compress || w.encoding == "" {
+		if len(buf) == 0 {
+			return nil
+		}
+		_, err := w.ResponseWriter.Write(buf)
+		return err
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges")
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, "\"") {
+		header.Set("ETag", "W/"+etag)
+	}
+	w.enc = w.cp.pools[w.encoding].Get().(Encoder)
+	w.enc.Reset(w.ResponseWriter)
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.enc.Write(buf)
+	return err
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if !w.decided {
+		if w.encoding == "" || !w.candidate() {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			w.buf = append(w.buf, data...)
+			if len(w.buf) < w.cp.config.MinLength {
+				return len(data), nil
+			}
+			return len(data), w.decide(true)
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush commits to compressing, a stream cannot wait for MinLength bytes
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(true)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Size() int {
+	if !w.Written() {
+		return noWritten
+	}
+	return w.size
+}
+
+// finish writes out what is still buffered and returns the encoder to its pool,
+// a panicking handler's partial output is dropped so Recovery can still respond
+func (w *compressWriter) finish(panicked bool) {
+	if panicked && !w.decided {
+		w.buf = nil
+		return
+	}
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.cp.pools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// addVary adds value to the Vary header unless it is already listed
+func addVary(header http.Header, value string) {
+	for _, line := range header.Values("Vary") {
+		for _, v := range strings.Split(line, ",") {
+			if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
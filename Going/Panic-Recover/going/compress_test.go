@@ -0,0 +1,101 @@
+package going
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCompressEngine() *Engine {
+	r := New()
+	r.Use(Compress(CompressConfig{ExcludedPaths: []string{"/raw"}}))
+	large := strings.Repeat("going ", 1000)
+	r.GET("/large", func(c *Context) {
+		c.String(http.StatusOK, large)
+	})
+	r.GET("/small", func(c *Context) {
+		c.String(http.StatusOK, "small")
+	})
+	r.GET("/raw/large", func(c *Context) {
+		c.String(http.StatusOK, large)
+	})
+	r.GET("/png", func(c *Context) {
+		c.SetHeader("Content-Type", "image/png")
+		c.Data(http.StatusOK, []byte(large))
+	})
+	return r
+}
+
+func TestCompressNegotiate(t *testing.T) {
+	cp := &compressor{config: CompressConfig{Encodings: []string{"gzip", "deflate"}}}
+	for header, want := range map[string]string{
+		"":                          "",
+		"br":                        "",
+		"gzip, deflate":             "gzip",
+		"deflate;q=1.0, gzip;q=0.5": "deflate",
+		"*;q=0.1, gzip;q=0":         "deflate",
+		"identity":                  "",
+	} {
+		if got := cp.negotiate(header); got != want {
+			t.Fatalf("negotiate(%q) should be %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	r := newCompressEngine()
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/large")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("large body should be gzipped, got %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != strings.Repeat("going ", 1000) {
+		t.Fatal("gzipped body should decode to the original")
+	}
+
+	w = get("/small")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "small" {
+		t.Fatalf("small body should not be compressed, got %v", w.Header())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatal("compressible responses should always vary on Accept-Encoding")
+	}
+
+	for _, path := range []string{"/raw/large", "/png"} {
+		if w = get(path); w.Header().Get("Content-Encoding") != "" {
+			t.Fatalf("%s should not be compressed", path)
+		}
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	r := New()
+	r.Use(Compress())
+	r.GET("/stream", func(c *Context) {
+		c.SetHeader("Content-Type", "text/event-stream")
+		c.Writer.Write([]byte("data: 1\n\n"))
+		c.Writer.Flush()
+	})
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("flushing should compress and reach the client, got %v", w.Header())
+	}
+}
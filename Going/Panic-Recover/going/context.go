@@ -2,15 +2,21 @@ package going
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 )
 
 type H map[string]interface{}
 
 type Context struct {
 	// origin objects
-	Writer http.ResponseWriter
+	Writer ResponseWriter
 	Req    *http.Request
 	// request info
 	Path   string
@@ -21,6 +27,15 @@ type Context struct {
 	// middleware
 	handlers []HandlerFunc
 	index    int
+	// errors collected by handlers and middlewares
+	Errors errorMsgs
+	// per-request key/value store shared by the handler chain
+	mu   sync.RWMutex
+	Keys map[string]any
+	// SameSite attribute of cookies set by this context
+	sameSite http.SameSite
+	// template funcs bound to this request
+	templateFuncs template.FuncMap
 	// engine pointer
 	engine *Engine
 }
@@ -30,7 +45,7 @@ func newContext(w http.ResponseWriter, req *http.Request) *Context {
 		Path:   req.URL.Path,
 		Method: req.Method,
 		Req:    req,
-		Writer: w,
+		Writer: newResponseWriter(w),
 		index:  -1,
 	}
 }
@@ -43,17 +58,103 @@ func (c *Context) Next() {
 	}
 }
 
-func (c *Context) Fail(code int, err string) {
+// Abort prevents pending handlers from being called
+func (c *Context) Abort() {
 	c.index = len(c.handlers)
+}
+
+// Fail aborts the chain and responds with err, as a problem document
+// when the engine has ProblemDetails enabled
+func (c *Context) Fail(code int, err string) {
+	c.Abort()
+	if c.useProblems() {
+		c.Problem(NewProblem(code, err))
+		return
+	}
 	c.JSON(code, H{"message": err})
 }
 
+// Error attaches an error to the current context, the engine's error handler
+// turns the collected errors into a response once the handler chain returned
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+	parsedError, ok := err.(*Error)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
+// Set stores a new key/value pair exclusively for this context
+func (c *Context) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+	c.Keys[key] = value
+}
+
+// Get returns the value for the given key, ie: (value, true)
+// If the value does not exist it returns (nil, false)
+func (c *Context) Get(key string) (value any, exists bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists = c.Keys[key]
+	return
+}
+
+// MustGet returns the value for the given key if it exists, otherwise it panics
+func (c *Context) MustGet(key string) any {
+	if value, exists := c.Get(key); exists {
+		return value
+	}
+	panic("Key \"" + key + "\" does not exist")
+}
+
+// GetString returns the value associated with the key as a string
+func (c *Context) GetString(key string) (s string) {
+	if val, ok := c.Get(key); ok && val != nil {
+		s, _ = val.(string)
+	}
+	return
+}
+
 func (c *Context) Param(key string) string {
 	value, _ := c.Params[key]
 	return value
 }
 
+// defaultMultipartMemory is the part of a multipart form kept in memory, like net/http
+const defaultMultipartMemory = 32 << 20
+
+// PostForm returns the form value for key. A body going over the limit of
+// BodyLimit or Decompress is answered with 413 right away, instead of the
+// handler responding as if the form was empty.
 func (c *Context) PostForm(key string) string {
+	if c.Req.Form == nil {
+		var err error
+		if strings.HasPrefix(c.Req.Header.Get("Content-Type"), "multipart/form-data") {
+			err = c.Req.ParseMultipartForm(defaultMultipartMemory)
+		} else {
+			err = c.Req.ParseForm()
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(err).SetType(ErrorTypeBind)
+			if body := exceededBody(c.Req.Body); body != nil {
+				body.reject(c)
+			} else if !c.Writer.Written() {
+				bodyTooLarge(c)
+			}
+		}
+	}
 	return c.Req.FormValue(key)
 }
 
@@ -61,6 +162,44 @@ func (c *Context) Query(key string) string {
 	return c.Req.URL.Query().Get(key)
 }
 
+// ClientIP returns the remote address of the client. When the engine has
+// ForwardedByClientIP enabled and the request comes from a trusted proxy,
+// X-Forwarded-For is read from the right, where proxies append, skipping
+// trusted proxies, so an address made up by the client is never returned.
+func (c *Context) ClientIP() string {
+	remote, _, err := net.SplitHostPort(strings.TrimSpace(c.Req.RemoteAddr))
+	if err != nil {
+		remote = c.Req.RemoteAddr
+	}
+	engine := c.engine
+	if engine == nil || !engine.ForwardedByClientIP {
+		return remote
+	}
+	// without a trusted proxy list only the direct peer is trusted
+	if len(engine.trustedProxies) > 0 {
+		if ip := net.ParseIP(remote); ip == nil || !engine.isTrustedProxy(ip) {
+			return remote
+		}
+	}
+	if forwarded := c.Req.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if i == 0 || !engine.isTrustedProxy(ip) {
+				return ip.String()
+			}
+		}
+		return remote
+	}
+	if ip := net.ParseIP(strings.TrimSpace(c.Req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return remote
+}
+
 func (c *Context) Status(code int) {
 	c.StatusCode = code
 	c.Writer.WriteHeader(code)
@@ -90,12 +229,38 @@ func (c *Context) Data(code int, data []byte) {
 	c.Writer.Write(data)
 }
 
+// Redirect responds with a redirect to location, code must be one of
+// 300, 301, 302, 303, 307 or 308
+func (c *Context) Redirect(code int, location string) {
+	switch code {
+	case http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusFound,
+		http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("going: can not redirect with status code %d", code))
+	}
+	http.Redirect(c.Writer, c.Req, location, code)
+}
+
 // HTML template render
 // refer https://golang.org/pkg/html/template/
 func (c *Context) HTML(code int, name string, data interface{}) {
+	// render into a buffer first, so a failing template still leaves
+	// the response untouched for a clean 500
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := c.renderHTML(buf, name, data); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
+		c.Fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
 	c.SetHeader("Content-Type", "text/html")
 	c.Status(code)
-	if err := c.engine.htmlTemplates.ExecuteTemplate(c.Writer, name, data); err != nil {
-		c.Fail(500, err.Error())
+	c.Writer.Write(buf.Bytes())
+}
+
+func (c *Context) renderHTML(w io.Writer, name string, data interface{}) error {
+	if c.engine == nil || c.engine.HTMLRender == nil {
+		return ErrNoHTMLRender
 	}
+	return c.engine.HTMLRender.Render(w, name, data, c.templateFuncs)
 }
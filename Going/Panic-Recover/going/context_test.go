@@ -0,0 +1,43 @@
+package going
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		forwarded bool
+		proxies   []string
+		remote    string
+		xff       string
+		want      string
+	}{
+		{"headers ignored by default", false, nil, "10.0.0.1:1234", "1.1.1.1", "10.0.0.1"},
+		{"rightmost hop of the direct proxy", true, nil, "10.0.0.1:1234", "6.6.6.6, 1.1.1.1", "1.1.1.1"},
+		{"trusted proxies are skipped", true, []string{"10.0.0.0/8"}, "10.0.0.1:1234", "6.6.6.6, 1.1.1.1, 10.0.0.2", "1.1.1.1"},
+		{"untrusted peer", true, []string{"10.0.0.0/8"}, "2.2.2.2:1234", "1.1.1.1", "2.2.2.2"},
+		{"all hops trusted", true, []string{"10.0.0.1", "10.0.0.2"}, "10.0.0.1:1234", "10.0.0.2", "10.0.0.2"},
+		{"invalid hop", true, nil, "10.0.0.1:1234", "1.1.1.1, bogus", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		engine := New()
+		engine.ForwardedByClientIP = tt.forwarded
+		if err := engine.SetTrustedProxies(tt.proxies); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remote
+		req.Header.Set("X-Forwarded-For", tt.xff)
+		c := newContext(httptest.NewRecorder(), req)
+		c.engine = engine
+		if got := c.ClientIP(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if err := New().SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected an invalid proxy to be rejected")
+	}
+}
@@ -0,0 +1,191 @@
+package going
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrNoCookieKeys       = errors.New("going: no secure cookie keys, call Engine.SetCookieKeys")
+	ErrInvalidCookieValue = errors.New("going: secure cookie value is invalid")
+	ErrExpiredCookieValue = errors.New("going: secure cookie value is expired")
+)
+
+// SetSameSite sets the SameSite attribute of cookies set by SetCookie and SetSecureCookie
+func (c *Context) SetSameSite(sameSite http.SameSite) {
+	c.sameSite = sameSite
+}
+
+// Cookie returns the unescaped value of the named request cookie,
+// http.ErrNoCookie is returned when it is missing
+func (c *Context) Cookie(name string) (string, error) {
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(cookie.Value)
+}
+
+// SetCookie adds a Set-Cookie header to the response, value is escaped
+func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		SameSite: c.sameSite,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+}
+
+// SetSecureCookie is SetCookie with a value signed, and encrypted when a block key is
+// configured, by the codec set with Engine.SetCookieKeys
+func (c *Context) SetSecureCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) error {
+	if c.engine == nil || c.engine.cookieCodec == nil {
+		return ErrNoCookieKeys
+	}
+	encoded, err := c.engine.cookieCodec.Encode(name, value)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(name, encoded, maxAge, path, domain, secure, httpOnly)
+	return nil
+}
+
+// SecureCookie returns the verified value of a cookie set with SetSecureCookie
+func (c *Context) SecureCookie(name string) (string, error) {
+	if c.engine == nil || c.engine.cookieCodec == nil {
+		return "", ErrNoCookieKeys
+	}
+	encoded, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return c.engine.cookieCodec.Decode(name, encoded)
+}
+
+// SetCookieKeys configures the secure cookie codec, see NewSecureCookie
+func (engine *Engine) SetCookieKeys(keyPairs ...[]byte) {
+	engine.cookieCodec = NewSecureCookie(keyPairs...)
+}
+
+type secureCookieKey struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+// SecureCookie encodes cookie values that are HMAC-SHA256 signed and, when a
+// block key is given, AES-GCM encrypted. The cookie name is authenticated
+// as well, so a value can not be moved to another cookie.
+type SecureCookie struct {
+	keys   []secureCookieKey
+	maxAge time.Duration
+}
+
+// NewSecureCookie creates a codec from hash key and block key pairs. Hash keys sign with
+// HMAC-SHA256 and must be at least 32 bytes, like HS256 secrets. The block key may be
+// nil to only sign, otherwise it must be 16, 24 or 32 bytes to select AES-128, AES-192 or
+// AES-256. Values are encoded with the first pair and decoded with any of them, so keys are
+// rotated by prepending a new pair and dropping the oldest one once its cookies expired.
+func NewSecureCookie(keyPairs ...[]byte) *SecureCookie {
+	if len(keyPairs) == 0 {
+		panic("going: secure cookie needs at least one hash key")
+	}
+	s := &SecureCookie{maxAge: 30 * 24 * time.Hour}
+	for i := 0; i < len(keyPairs); i += 2 {
+		key := secureCookieKey{hashKey: keyPairs[i]}
+		if len(key.hashKey) < minHMACKeyLength {
+			panic(fmt.Sprintf("going: secure cookie hash key must be at least %d bytes", minHMACKeyLength))
+		}
+		if i+1 < len(keyPairs) && keyPairs[i+1] != nil {
+			block, err := aes.NewCipher(keyPairs[i+1])
+			if err != nil {
+				panic(err)
+			}
+			if key.aead, err = cipher.NewGCM(block); err != nil {
+				panic(err)
+			}
+		}
+		s.keys = append(s.keys, key)
+	}
+	return s
+}
+
+// MaxAge sets how long encoded values are accepted, 30 days by default, 0 disables the check
+func (s *SecureCookie) MaxAge(d time.Duration) *SecureCookie {
+	s.maxAge = d
+	return s
+}
+
+// Encode signs and encrypts value for the cookie called name
+func (s *SecureCookie) Encode(name, value string) (string, error) {
+	key := s.keys[0]
+	payload := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(payload, uint64(time.Now().Unix()))
+	payload = append(payload, value...)
+	if key.aead != nil {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		payload = key.aead.Seal(nonce, nonce, payload, []byte(name))
+	}
+	payload = append(payload, cookieMAC(key.hashKey, name, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Decode verifies an encoded value for the cookie called name, trying every key pair
+func (s *SecureCookie) Decode(name, encoded string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < sha256.Size {
+		return "", ErrInvalidCookieValue
+	}
+	payload, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	for _, key := range s.keys {
+		if !hmac.Equal(cookieMAC(key.hashKey, name, payload), mac) {
+			continue
+		}
+		plain := payload
+		if key.aead != nil {
+			nonceSize := key.aead.NonceSize()
+			if len(payload) < nonceSize {
+				return "", ErrInvalidCookieValue
+			}
+			if plain, err = key.aead.Open(nil, payload[:nonceSize], payload[nonceSize:], []byte(name)); err != nil {
+				return "", ErrInvalidCookieValue
+			}
+		}
+		if len(plain) < 8 {
+			return "", ErrInvalidCookieValue
+		}
+		created := time.Unix(int64(binary.BigEndian.Uint64(plain[:8])), 0)
+		if s.maxAge > 0 && time.Since(created) > s.maxAge {
+			return "", ErrExpiredCookieValue
+		}
+		return string(plain[8:]), nil
+	}
+	return "", ErrInvalidCookieValue
+}
+
+func cookieMAC(hashKey []byte, name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
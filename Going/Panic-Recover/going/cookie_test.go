@@ -0,0 +1,75 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecureCookieRotation(t *testing.T) {
+	oldKeys := [][]byte{[]byte("tenet-going-old-cookie-hash-0032"), []byte("0123456789abcdef")}
+	old := NewSecureCookie(oldKeys...)
+	encoded, err := old.Encode("session", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := NewSecureCookie(append([][]byte{[]byte("tenet-going-new-cookie-hash-0032"), nil}, oldKeys...)...)
+	if value, err := rotated.Decode("session", encoded); err != nil || value != "alice" {
+		t.Fatalf("old values should decode after rotation, got %q %v", value, err)
+	}
+	if _, err := rotated.Decode("other", encoded); err != ErrInvalidCookieValue {
+		t.Fatalf("value should be bound to the cookie name, got %v", err)
+	}
+	fresh, _ := rotated.Encode("session", "bob")
+	if _, err := old.Decode("session", fresh); err != ErrInvalidCookieValue {
+		t.Fatalf("new values should use the new key, got %v", err)
+	}
+}
+
+func TestSecureCookieShortHashKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected NewSecureCookie to reject a short hash key")
+		}
+	}()
+	NewSecureCookie([]byte("hash-key"))
+}
+
+func TestContextSecureCookie(t *testing.T) {
+	r := New()
+	r.SetCookieKeys([]byte("tenet-going-cookie-hash-key-0032"), []byte("0123456789abcdef0123456789abcdef"))
+	r.GET("/set", func(c *Context) {
+		c.SetSameSite(http.SameSiteStrictMode)
+		c.SetCookie("plain", "a b", 60, "", "", false, true)
+		if err := c.SetSecureCookie("secret", "tenet", 60, "", "", true, true); err != nil {
+			t.Fatal(err)
+		}
+	})
+	r.GET("/get", func(c *Context) {
+		plain, _ := c.Cookie("plain")
+		secret, err := c.SecureCookie("secret")
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, plain+"|"+secret)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/set", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].SameSite != http.SameSiteStrictMode || cookies[1].Value == "tenet" {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+
+	req := httptest.NewRequest("GET", "/get", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "a b|tenet" {
+		t.Fatalf("cookies should round trip, got %d %s", w.Code, w.Body.String())
+	}
+}
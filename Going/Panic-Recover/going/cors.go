@@ -0,0 +1,148 @@
+package going
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig defines the config for the CORS middleware
+type CORSConfig struct {
+	// AllowOrigins lists exact origins, "*" for any origin,
+	// or wildcard subdomains such as "https://*.example.com"
+	AllowOrigins []string
+	// AllowOriginFunc is consulted when no entry of AllowOrigins matched
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods defaults to GET, POST, PUT, PATCH, DELETE, HEAD
+	AllowMethods []string
+	// AllowHeaders defaults to the headers the preflight asked for
+	AllowHeaders []string
+	// ExposeHeaders lists response headers browsers may read
+	ExposeHeaders []string
+	// AllowCredentials lets browsers send cookies and HTTP auth, it can not be
+	// combined with "*" in AllowOrigins, use AllowOriginFunc to vet origins instead
+	AllowCredentials bool
+	// MaxAge is how long the preflight result may be cached, 0 omits the header
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+type corsOrigins struct {
+	all       bool
+	exact     map[string]bool
+	wildcards [][2]string // prefix and suffix around the *
+	fn        func(origin string) bool
+}
+
+func newCORSOrigins(config CORSConfig) *corsOrigins {
+	o := &corsOrigins{exact: make(map[string]bool), fn: config.AllowOriginFunc}
+	for _, origin := range config.AllowOrigins {
+		origin = strings.ToLower(origin)
+		switch strings.Count(origin, "*") {
+		case 0:
+			o.exact[origin] = true
+		case 1:
+			if origin == "*" {
+				o.all = true
+				continue
+			}
+			i := strings.IndexByte(origin, '*')
+			o.wildcards = append(o.wildcards, [2]string{origin[:i], origin[i+1:]})
+		default:
+			panic("going: only one * is allowed in CORS origin " + origin)
+		}
+	}
+	return o
+}
+
+func (o *corsOrigins) allowed(origin string) bool {
+	if o.all {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if o.exact[lower] {
+		return true
+	}
+	for _, w := range o.wildcards {
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+	return o.fn != nil && o.fn(origin)
+}
+
+// CORS returns a middleware handling cross-origin requests,
+// preflight requests are answered directly, whether or not an OPTIONS route exists
+func CORS(config CORSConfig) HandlerFunc {
+	origins := newCORSOrigins(config)
+	if origins.all && config.AllowCredentials {
+		// reflecting any origin with credentials would let every site act as the user
+		panic(`going: CORS AllowOrigins "*" can not be used with AllowCredentials`)
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = defaultCORSMethods
+	}
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(config.MaxAge/time.Second), 10)
+	}
+
+	return func(c *Context) {
+		origin := c.Req.Header.Get("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		preflight := c.Method == http.MethodOptions && c.Req.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !origins.allowed(origin) {
+			if preflight {
+				c.Abort()
+				c.Status(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if origins.all {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.Req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if maxAge != "" {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.Abort()
+		c.Status(http.StatusNoContent)
+	}
+}
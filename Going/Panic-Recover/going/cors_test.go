@@ -0,0 +1,72 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSEngine() *Engine {
+	r := New()
+	r.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.tenet.dev"},
+		ExposeHeaders:    []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	r.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "users")
+	})
+	return r
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := newCORSEngine()
+	req := httptest.NewRequest("OPTIONS", "/users", nil)
+	req.Header.Set("Origin", "https://api.tenet.dev")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight should be answered with 204 without an OPTIONS route, got %d", w.Code)
+	}
+	h := w.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://api.tenet.dev" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Allow-Headers") != "Authorization" ||
+		h.Get("Access-Control-Max-Age") != "3600" {
+		t.Fatalf("unexpected preflight headers %v", h)
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	r := newCORSEngine()
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "users" || w.Header().Get("Access-Control-Expose-Headers") != "X-Total" {
+		t.Fatalf("allowed origin should reach the handler with CORS headers, got %v", w.Header())
+	}
+
+	req = httptest.NewRequest("OPTIONS", "/users", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("disallowed origin should be rejected, got %d", w.Code)
+	}
+}
+
+func TestCORSAnyOriginWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`expected "*" with credentials to be rejected`)
+		}
+	}()
+	CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+}
@@ -0,0 +1,242 @@
+package going
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CSRFTokenKey is the Context key the masked token for the current request is stored under
+const CSRFTokenKey = "going/csrfToken"
+
+// CSRFMode selects where the secret a token is checked against lives
+type CSRFMode int
+
+const (
+	// CSRFDoubleSubmit keeps the secret in a cookie and expects it back in the form or header
+	CSRFDoubleSubmit CSRFMode = iota
+	// CSRFSynchronizer keeps the secret on the server, the cookie only carries an opaque id
+	CSRFSynchronizer
+)
+
+// CSRFStore keeps synchronizer token secrets by id
+type CSRFStore interface {
+	Load(id string) (secret string, ok bool)
+	Save(id, secret string)
+}
+
+// CSRFConfig defines the config for the CSRF middleware
+type CSRFConfig struct {
+	Mode CSRFMode
+	// Store keeps the secrets in CSRFSynchronizer mode, in memory by default
+	Store CSRFStore
+	// CookieName defaults to "_csrf"
+	CookieName     string
+	CookiePath     string
+	CookieDomain   string
+	CookieSecure   bool
+	CookieSameSite http.SameSite
+	// HeaderName is checked before the form field, "X-CSRF-Token" by default
+	HeaderName string
+	// FormField defaults to "_csrf"
+	FormField string
+	// SafeMethods are exempt from checks, GET, HEAD, OPTIONS and TRACE by default
+	SafeMethods []string
+	// ErrorHandler answers requests with a missing or wrong token, a 403 by default
+	ErrorHandler HandlerFunc
+}
+
+const csrfSecretLength = 32
+
+// CSRF returns a middleware protecting unsafe methods against cross-site request forgery.
+// Templates rendered with Context.HTML can use {{csrfToken}} or {{csrfField}} in forms.
+func CSRF(config ...CSRFConfig) HandlerFunc {
+	var cfg CSRFConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "_csrf"
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.CookieSameSite == 0 {
+		cfg.CookieSameSite = http.SameSiteLaxMode
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = "_csrf"
+	}
+	if cfg.SafeMethods == nil {
+		cfg.SafeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}
+	}
+	if cfg.Mode == CSRFSynchronizer && cfg.Store == nil {
+		cfg.Store = NewCSRFMemoryStore(24 * time.Hour)
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = func(c *Context) {
+			c.Fail(http.StatusForbidden, "invalid CSRF token")
+		}
+	}
+	safe := make(map[string]bool, len(cfg.SafeMethods))
+	for _, method := range cfg.SafeMethods {
+		safe[method] = true
+	}
+
+	return func(c *Context) {
+		secret, ok := cfg.loadSecret(c)
+		if !safe[c.Method] {
+			token := c.Req.Header.Get(cfg.HeaderName)
+			if token == "" {
+				token = c.PostForm(cfg.FormField)
+			}
+			if !ok || !validCSRFToken(token, secret) {
+				c.Abort()
+				cfg.ErrorHandler(c)
+				return
+			}
+		}
+		if !ok {
+			secret = cfg.saveSecret(c)
+		}
+
+		token := maskCSRFToken(secret)
+		c.Set(CSRFTokenKey, token)
+		c.bindTemplateFunc("csrfToken", func() string { return token })
+		field := cfg.FormField
+		c.bindTemplateFunc("csrfField", func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(field) + `" value="` + token + `">`)
+		})
+		c.Next()
+	}
+}
+
+// CSRFToken returns the token to embed in forms or send in the CSRF header
+func (c *Context) CSRFToken() string {
+	return c.GetString(CSRFTokenKey)
+}
+
+func (cfg *CSRFConfig) loadSecret(c *Context) (string, bool) {
+	cookie, err := c.Req.Cookie(cfg.CookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	if cfg.Mode == CSRFSynchronizer {
+		return cfg.Store.Load(cookie.Value)
+	}
+	if raw, err := base64.RawURLEncoding.DecodeString(cookie.Value); err != nil || len(raw) != csrfSecretLength {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+func (cfg *CSRFConfig) saveSecret(c *Context) string {
+	secret := randomToken(csrfSecretLength)
+	value := secret
+	if cfg.Mode == CSRFSynchronizer {
+		value = randomToken(csrfSecretLength)
+		cfg.Store.Save(value, secret)
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    value,
+		Path:     cfg.CookiePath,
+		Domain:   cfg.CookieDomain,
+		Secure:   cfg.CookieSecure,
+		HttpOnly: true,
+		SameSite: cfg.CookieSameSite,
+	})
+	return secret
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// maskCSRFToken xors the secret with a one-time pad so the token differs on every
+// response, which defeats compression based attacks such as BREACH
+func maskCSRFToken(secret string) string {
+	raw, _ := base64.RawURLEncoding.DecodeString(secret)
+	pad := make([]byte, len(raw))
+	if _, err := rand.Read(pad); err != nil {
+		panic(err)
+	}
+	masked := make([]byte, 2*len(raw))
+	copy(masked, pad)
+	for i := range raw {
+		masked[len(raw)+i] = pad[i] ^ raw[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+func validCSRFToken(token, secret string) bool {
+	masked, err := base64.RawURLEncoding.DecodeString(token)
+	raw, err2 := base64.RawURLEncoding.DecodeString(secret)
+	if err != nil || err2 != nil || len(raw) == 0 || len(masked) != 2*len(raw) {
+		return false
+	}
+	unmasked := make([]byte, len(raw))
+	for i := range raw {
+		unmasked[i] = masked[i] ^ masked[len(raw)+i]
+	}
+	return subtle.ConstantTimeCompare(unmasked, raw) == 1
+}
+
+type csrfEntry struct {
+	secret  string
+	expires time.Time
+}
+
+// CSRFMemoryStore is an in-memory CSRFStore whose secrets expire after a TTL
+type CSRFMemoryStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]csrfEntry
+	lastSweep time.Time
+}
+
+// NewCSRFMemoryStore creates a CSRFStore for a single instance
+func NewCSRFMemoryStore(ttl time.Duration) *CSRFMemoryStore {
+	return &CSRFMemoryStore{ttl: ttl, entries: make(map[string]csrfEntry)}
+}
+
+// Load implements CSRFStore, using a secret extends its lifetime
+func (s *CSRFMemoryStore) Load(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	entry, ok := s.entries[id]
+	if !ok || now.After(entry.expires) {
+		return "", false
+	}
+	entry.expires = now.Add(s.ttl)
+	s.entries[id] = entry
+	return entry.secret, true
+}
+
+// Save implements CSRFStore
+func (s *CSRFMemoryStore) Save(id, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= s.ttl {
+		s.lastSweep = now
+		for key, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, key)
+			}
+		}
+	}
+	s.entries[id] = csrfEntry{secret: secret, expires: now.Add(s.ttl)}
+}
@@ -0,0 +1,61 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var csrfFieldRe = regexp.MustCompile(`name="_csrf" value="([^"]+)"`)
+
+func testCSRF(t *testing.T, mode CSRFMode) {
+	r := New()
+	r.Use(CSRF(CSRFConfig{Mode: mode}))
+	r.LoadHTMLGlob("testdata/*.tmpl")
+	r.GET("/form", func(c *Context) {
+		c.HTML(http.StatusOK, "form.tmpl", nil)
+	})
+	r.POST("/form", func(c *Context) {
+		c.String(http.StatusOK, "saved")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+	match := csrfFieldRe.FindStringSubmatch(w.Body.String())
+	cookies := w.Result().Cookies()
+	if match == nil || len(cookies) != 1 {
+		t.Fatalf("form should render a token and set a cookie, got %s", w.Body.String())
+	}
+
+	post := func(token string, withCookie bool) int {
+		form := url.Values{"_csrf": {token}}
+		req := httptest.NewRequest("POST", "/form", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := post(match[1], true); code != http.StatusOK {
+		t.Fatalf("valid token should pass, got %d", code)
+	}
+	if code := post(match[1], false); code != http.StatusForbidden {
+		t.Fatalf("token without cookie should be rejected, got %d", code)
+	}
+	if code := post("forged", true); code != http.StatusForbidden {
+		t.Fatalf("forged token should be rejected, got %d", code)
+	}
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	testCSRF(t, CSRFDoubleSubmit)
+}
+
+func TestCSRFSynchronizer(t *testing.T) {
+	testCSRF(t, CSRFSynchronizer)
+}
@@ -0,0 +1,60 @@
+package going
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressConfig defines the config for the Decompress middleware
+type DecompressConfig struct {
+	// MaxSize caps the decompressed body to protect against zip bombs, 10MB by default
+	MaxSize int64
+}
+
+// Decompress returns a middleware that transparently decodes gzip and deflate request bodies,
+// so PostForm and JSON decoding see the plain payload
+func Decompress(config ...DecompressConfig) HandlerFunc {
+	var cfg DecompressConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 10 << 20
+	}
+	return func(c *Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.Req.Header.Get("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Req.Body == nil || c.Req.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		var reader io.ReadCloser
+		var err error
+		switch encoding {
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(c.Req.Body)
+		case "deflate":
+			reader, err = zlib.NewReader(c.Req.Body)
+		default:
+			c.Fail(http.StatusUnsupportedMediaType, "unsupported Content-Encoding "+encoding)
+			return
+		}
+		if err != nil {
+			c.Fail(http.StatusBadRequest, "malformed "+encoding+" request body")
+			return
+		}
+
+		body := newLimitedBody(reader, c.Req.Body, cfg.MaxSize, bodyTooLarge)
+		c.Req.Body = body
+		c.Req.Header.Del("Content-Encoding")
+		c.Req.Header.Del("Content-Length")
+		c.Req.ContentLength = -1
+		c.Next()
+		if body.exceeded {
+			body.reject(c)
+		}
+	}
+}
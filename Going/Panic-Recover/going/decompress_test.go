@@ -0,0 +1,60 @@
+package going
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipBody(s string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return &buf
+}
+
+func TestDecompress(t *testing.T) {
+	r := New()
+	r.Use(Decompress(DecompressConfig{MaxSize: 64}))
+	r.POST("/form", func(c *Context) {
+		c.String(http.StatusOK, c.PostForm("name"))
+	})
+	r.POST("/echo", func(c *Context) {
+		data, err := io.ReadAll(c.Req.Body)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, data)
+	})
+
+	req := httptest.NewRequest("POST", "/form", gzipBody("name=going"))
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "going" {
+		t.Fatalf("gzipped form should be decoded, got %q", w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/echo", gzipBody(strings.Repeat("a", 1024)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("bodies over MaxSize should be rejected with 413, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/echo", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("malformed bodies should be rejected with 400, got %d", w.Code)
+	}
+}
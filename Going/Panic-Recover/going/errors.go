@@ -0,0 +1,180 @@
+package going
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorType is a bit set describing where an error came from and who may see it
+type ErrorType uint64
+
+const (
+	// ErrorTypeBind is used when decoding the request failed
+	ErrorTypeBind ErrorType = 1 << 63
+	// ErrorTypeRender is used when writing the response failed
+	ErrorTypeRender ErrorType = 1 << 62
+	// ErrorTypePrivate marks errors that must not leak to the client
+	ErrorTypePrivate ErrorType = 1 << 0
+	// ErrorTypePublic marks errors whose message may be sent to the client
+	ErrorTypePublic ErrorType = 1 << 1
+	// ErrorTypeAny matches every error type
+	ErrorTypeAny ErrorType = 1<<64 - 1
+)
+
+// Error wraps an error collected on the Context
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta any
+}
+
+type errorMsgs []*Error
+
+var _ error = (*Error)(nil)
+
+// SetType sets the error's type
+func (msg *Error) SetType(flags ErrorType) *Error {
+	msg.Type = flags
+	return msg
+}
+
+// SetMeta sets the error's meta data
+func (msg *Error) SetMeta(data any) *Error {
+	msg.Meta = data
+	return msg
+}
+
+// JSON creates a properly formatted JSON object
+func (msg *Error) JSON() any {
+	json := H{}
+	if msg.Meta != nil {
+		switch meta := msg.Meta.(type) {
+		case H:
+			for key, value := range meta {
+				json[key] = value
+			}
+		case map[string]any:
+			for key, value := range meta {
+				json[key] = value
+			}
+		default:
+			json["meta"] = msg.Meta
+		}
+	}
+	if _, ok := json["error"]; !ok {
+		json["error"] = msg.Error()
+	}
+	return json
+}
+
+// Error implements the error interface
+func (msg *Error) Error() string {
+	return msg.Err.Error()
+}
+
+// IsType reports whether the error has any of the given flags
+func (msg *Error) IsType(flags ErrorType) bool {
+	return (msg.Type & flags) > 0
+}
+
+// Unwrap returns the wrapped error, to allow interoperability with errors.Is(), errors.As()
+func (msg *Error) Unwrap() error {
+	return msg.Err
+}
+
+// ByType returns a readonly copy filtered by type
+func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
+	if len(a) == 0 {
+		return nil
+	}
+	if typ == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, msg := range a {
+		if msg.IsType(typ) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Last returns the last error in the slice, or nil if it is empty
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
+// Errors returns the error messages in the order they were collected
+func (a errorMsgs) Errors() []string {
+	if len(a) == 0 {
+		return nil
+	}
+	errorStrings := make([]string, len(a))
+	for i, err := range a {
+		errorStrings[i] = err.Error()
+	}
+	return errorStrings
+}
+
+// JSON returns the JSON objects of all errors, always as a list
+func (a errorMsgs) JSON() []any {
+	if len(a) == 0 {
+		return nil
+	}
+	jsonData := make([]any, len(a))
+	for i, err := range a {
+		jsonData[i] = err.JSON()
+	}
+	return jsonData
+}
+
+func (a errorMsgs) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	var buffer strings.Builder
+	for i, msg := range a {
+		fmt.Fprintf(&buffer, "Error #%02d: %s\n", i+1, msg.Err)
+		if msg.Meta != nil {
+			fmt.Fprintf(&buffer, "     Meta: %v\n", msg.Meta)
+		}
+	}
+	return buffer.String()
+}
+
+// defaultErrorHandler answers with the collected errors unless a response was already sent,
+// only public errors expose their message and a Problem is sent as it is
+func defaultErrorHandler(c *Context) {
+	if c.Writer.Written() {
+		return
+	}
+	// a Problem is written for the client and carries its own status
+	for i := len(c.Errors) - 1; i >= 0; i-- {
+		var p *Problem
+		if errors.As(c.Errors[i].Err, &p) {
+			c.Abort()
+			c.Problem(p)
+			return
+		}
+	}
+	code := c.Writer.Status()
+	if code < http.StatusBadRequest {
+		code = http.StatusInternalServerError
+	}
+	public := c.Errors.ByType(ErrorTypePublic)
+	if len(public) == 0 {
+		c.Fail(code, http.StatusText(code))
+		return
+	}
+	c.Abort()
+	if c.useProblems() {
+		c.Problem(NewProblem(code, public.Last().Error()).With("errors", public.JSON()))
+		return
+	}
+	c.JSON(code, H{"message": public.Last().Error(), "errors": public.JSON()})
+}
@@ -0,0 +1,80 @@
+package going
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorsByType(t *testing.T) {
+	c := &Context{}
+	c.Error(errors.New("private"))
+	c.Error(errors.New("public")).SetType(ErrorTypePublic).SetMeta(H{"field": "name"})
+	c.Error(errors.New("bind")).SetType(ErrorTypeBind | ErrorTypePublic)
+
+	if len(c.Errors.ByType(ErrorTypePublic)) != 2 {
+		t.Fatal("there should be 2 public errors")
+	}
+	if len(c.Errors.ByType(ErrorTypeAny)) != 3 {
+		t.Fatal("ErrorTypeAny should match every error")
+	}
+	if c.Errors.ByType(ErrorTypeBind).Last().Error() != "bind" {
+		t.Fatal("last bind error should be 'bind'")
+	}
+	data, _ := json.Marshal(c.Errors.ByType(ErrorTypePublic).JSON())
+	if string(data) != `[{"error":"public","field":"name"},{"error":"bind"}]` {
+		t.Fatalf("unexpected JSON %s", data)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	r := New()
+	r.GET("/private", func(c *Context) {
+		c.Error(errors.New("db is down"))
+	})
+	r.GET("/public", func(c *Context) {
+		c.Status(http.StatusBadRequest)
+		c.Error(errors.New("name is required")).SetType(ErrorTypePublic)
+	})
+	r.GET("/written", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+		c.Error(errors.New("ignored"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/private", nil))
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "{\"message\":\"Internal Server Error\"}\n" {
+		t.Fatalf("private errors should not leak, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/public", nil))
+	if w.Code != http.StatusBadRequest || w.Body.String() != "{\"errors\":[{\"error\":\"name is required\"}],\"message\":\"name is required\"}\n" {
+		t.Fatalf("public errors should be reported, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/written", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("written responses should be kept, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorHandlerProblem(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) {
+		c.Error(errors.New("db is down"))
+		c.Error(fmt.Errorf("create user: %w", NewProblem(http.StatusConflict, "dup")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusConflict || w.Header().Get("Content-Type") != "application/problem+json" ||
+		!strings.Contains(w.Body.String(), `"detail":"dup"`) {
+		t.Fatalf("a Problem should be rendered with its own status, got %d %s", w.Code, w.Body.String())
+	}
+}
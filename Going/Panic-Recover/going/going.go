@@ -1,10 +1,13 @@
 package going
 
 import (
+	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
-	"path"
+	"os"
 	"strings"
 )
 
@@ -22,16 +25,41 @@ type (
 
 	Engine struct {
 		*RouterGroup
-		router        *router
-		groups        []*RouterGroup     // store all groups
-		htmlTemplates *template.Template // for html render
-		funcMap       template.FuncMap   // for html render
+		// ProblemDetails makes the built-in 404/405/500 responses, Fail and the
+		// default error handler answer with RFC 7807 application/problem+json
+		ProblemDetails bool
+		// ForwardedByClientIP makes ClientIP trust X-Forwarded-For and X-Real-IP sent
+		// by a trusted proxy, only enable it behind a proxy that sets them. Without
+		// SetTrustedProxies the direct peer is the only trusted proxy.
+		ForwardedByClientIP bool
+		// HTMLRender renders Context.HTML, set by the LoadHTML* methods
+		HTMLRender HTMLRender
+		// RedirectTrailingSlash redirects a path matching a route only but for
+		// a trailing slash to the path of the route, it is enabled by New
+		RedirectTrailingSlash bool
+		// RedirectFixedPath redirects a path matching no route to the route it
+		// matches once cleaned, without ./.. segments and repeated slashes, and
+		// compared case-insensitively
+		RedirectFixedPath bool
+		// RemoveExtraSlash routes a path with repeated slashes as if they were
+		// single ones, without redirecting
+		RemoveExtraSlash bool
+
+		router         *router
+		groups         []*RouterGroup    // store all groups
+		funcMap        template.FuncMap  // for html render
+		errorHandler   HandlerFunc       // turns Context.Errors into a response
+		cookieCodec    *SecureCookie     // for signed and encrypted cookies
+		assets         map[string]string // asset name to fingerprinted URL, see StaticConfig.Fingerprint
+		routes         []*RouteInfo      // in registration order
+		namedRoutes    map[string]*RouteInfo
+		trustedProxies []*net.IPNet // see SetTrustedProxies
 	}
 )
 
 // New is the constructor of going.Engine
 func New() *Engine {
-	engine := &Engine{router: newRouter()}
+	engine := &Engine{router: newRouter(), errorHandler: defaultErrorHandler, RedirectTrailingSlash: true}
 	engine.RouterGroup = &RouterGroup{engine: engine}
 	engine.groups = []*RouterGroup{engine.RouterGroup}
 	return engine
@@ -62,44 +90,37 @@ func (group *RouterGroup) Use(middlewares ...HandlerFunc) {
 	group.middlewares = append(group.middlewares, middlewares...)
 }
 
-func (group *RouterGroup) addRoute(method string, comp string, handler HandlerFunc) {
+func (group *RouterGroup) addRoute(method string, comp string, handler HandlerFunc) *RouteInfo {
 	pattern := group.prefix + comp
-	log.Printf("Route %4s - %s", method, pattern)
-	group.engine.router.addRoute(method, pattern, handler)
+	engine := group.engine
+	engine.router.addRoute(method, pattern, handler)
+	route := &RouteInfo{Method: method, Path: pattern, Handler: nameOfFunction(handler), engine: engine}
+	engine.addRouteInfo(route)
+	if IsDebugging() {
+		log.Printf("[GOING-debug] %-6s %-25s --> %s (%d middlewares)", method, pattern, route.Handler, engine.middlewareCount(pattern))
+	}
+	return route
 }
 
 // GET defines the method to add GET request
-func (group *RouterGroup) GET(pattern string, handler HandlerFunc) {
-	group.addRoute("GET", pattern, handler)
+func (group *RouterGroup) GET(pattern string, handler HandlerFunc) *RouteInfo {
+	return group.addRoute("GET", pattern, handler)
 }
 
 // POST defines the method to add POST request
-func (group *RouterGroup) POST(pattern string, handler HandlerFunc) {
-	group.addRoute("POST", pattern, handler)
-}
-
-// create static handler
-func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
-	absolutePath := path.Join(group.prefix, relativePath)
-	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
-	return func(c *Context) {
-		file := c.Param("filepath")
-		// Check if file exists and/or if we have permission to access it
-		if _, err := fs.Open(file); err != nil {
-			c.Status(http.StatusNotFound)
-			return
-		}
-
-		fileServer.ServeHTTP(c.Writer, c.Req)
-	}
+func (group *RouterGroup) POST(pattern string, handler HandlerFunc) *RouteInfo {
+	return group.addRoute("POST", pattern, handler)
 }
 
 // serve static files
 func (group *RouterGroup) Static(relativePath string, root string) {
-	handler := group.createStaticHandler(relativePath, http.Dir(root))
-	urlPattern := path.Join(relativePath, "/*filepath")
-	// Register GET handlers
-	group.GET(urlPattern, handler)
+	group.StaticFS(relativePath, os.DirFS(root))
+}
+
+// StaticFS serves the files of fsys, e.g. an embed.FS, under relativePath.
+// Directories are listed, see StaticWithConfig for more control.
+func (group *RouterGroup) StaticFS(relativePath string, fsys fs.FS) {
+	group.StaticWithConfig(relativePath, StaticConfig{Root: fsys, Browse: true})
 }
 
 // for custom render function
@@ -107,8 +128,47 @@ func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
 	engine.funcMap = funcMap
 }
 
-func (engine *Engine) LoadHTMLGlob(pattern string) {
-	engine.htmlTemplates = template.Must(template.New("").Funcs(engine.funcMap).ParseGlob(pattern))
+// SetErrorHandler replaces the handler run after the chain returned with errors on the Context,
+// nil disables centralized error handling
+func (engine *Engine) SetErrorHandler(handler HandlerFunc) {
+	engine.errorHandler = handler
+}
+
+// SetTrustedProxies sets the IPs and CIDR ranges of the proxies whose forwarding
+// headers ClientIP trusts when ForwardedByClientIP is enabled
+func (engine *Engine) SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("going: invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("going: invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	engine.trustedProxies = nets
+	return nil
+}
+
+// isTrustedProxy reports whether ip belongs to a proxy set with SetTrustedProxies
+func (engine *Engine) isTrustedProxy(ip net.IP) bool {
+	for _, ipNet := range engine.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // Run defines the method to start a http server
@@ -116,15 +176,26 @@ func (engine *Engine) Run(addr string) (err error) {
 	return http.ListenAndServe(addr, engine)
 }
 
-func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+// groupMiddlewares returns the middlewares of every group whose prefix the route pattern starts with
+func (engine *Engine) groupMiddlewares(pattern string) []HandlerFunc {
 	var middlewares []HandlerFunc
 	for _, group := range engine.groups {
-		if strings.HasPrefix(req.URL.Path, group.prefix) {
+		if strings.HasPrefix(pattern, group.prefix) {
 			middlewares = append(middlewares, group.middlewares...)
 		}
 	}
+	return middlewares
+}
+
+func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := newContext(w, req)
-	c.handlers = middlewares
 	c.engine = engine
+	if engine.RemoveExtraSlash {
+		c.Path = removeExtraSlash(c.Path)
+	}
 	engine.router.handle(c)
+	if len(c.Errors) > 0 && engine.errorHandler != nil {
+		engine.errorHandler(c)
+	}
+	c.Writer.WriteHeaderNow()
 }
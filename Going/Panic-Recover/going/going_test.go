@@ -1,6 +1,10 @@
 package going
 
-import "testing"
+import (
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
 
 func TestNestedGroup(t *testing.T) {
 	r := New()
@@ -14,3 +18,22 @@ func TestNestedGroup(t *testing.T) {
 		t.Fatal("v3 prefix should be /v1/v2/v3")
 	}
 }
+
+func TestStaticFS(t *testing.T) {
+	r := New()
+	r.StaticFS("/assets", fstest.MapFS{
+		"css/app.css": &fstest.MapFile{Data: []byte("body{}")},
+	})
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	if w := serve("/assets/css/app.css"); w.Code != 200 || w.Body.String() != "body{}" {
+		t.Fatalf("expected the file, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve("/assets/css/missing.css"); w.Code != 404 {
+		t.Fatalf("expected 404 for a missing file, got %d", w.Code)
+	}
+}
@@ -0,0 +1,189 @@
+package going
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// JWTKey is a key used to verify token signatures, Key is a []byte secret of at least 32 bytes
+// for HS256, an *rsa.PublicKey for RS256 or an *ecdsa.PublicKey on P-256 for ES256
+type JWTKey struct {
+	ID        string
+	Algorithm string
+	Key       any
+}
+
+// minHMACKeyLength is the smallest HS256 secret accepted, RFC 7518 requires a key
+// at least as long as the hash output so it can not be guessed or left empty
+const minHMACKeyLength = 32
+
+// JWTKeySet holds the keys a JWT middleware accepts, looked up by kid
+type JWTKeySet struct {
+	keys []JWTKey
+}
+
+// NewJWTKeySet creates a key set, keys without Algorithm get it inferred from the key type
+func NewJWTKeySet(keys ...JWTKey) *JWTKeySet {
+	set := &JWTKeySet{}
+	for _, key := range keys {
+		set.Add(key)
+	}
+	return set
+}
+
+// Add adds key to the set, it panics when the algorithm can not be inferred
+// or an HS256 secret is shorter than 32 bytes
+func (s *JWTKeySet) Add(key JWTKey) {
+	if key.Algorithm == "" {
+		key.Algorithm = inferJWTAlgorithm(key.Key)
+	}
+	if key.Algorithm == "" {
+		panic(fmt.Sprintf("going: unsupported JWT key type %T", key.Key))
+	}
+	if secret, ok := key.Key.([]byte); ok && len(secret) < minHMACKeyLength {
+		panic(fmt.Sprintf("going: HS256 secret %q must be at least %d bytes", key.ID, minHMACKeyLength))
+	}
+	s.keys = append(s.keys, key)
+}
+
+// lookup returns the candidate keys for a token, a key is only ever used with its own
+// algorithm so an RSA public key can not be abused as an HMAC secret
+func (s *JWTKeySet) lookup(kid, alg string) []JWTKey {
+	keys := make([]JWTKey, 0, 1)
+	for _, key := range s.keys {
+		if key.Algorithm != alg {
+			continue
+		}
+		if kid != "" && key.ID != "" && key.ID != kid {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func inferJWTAlgorithm(key any) string {
+	switch k := key.(type) {
+	case []byte:
+		return "HS256"
+	case *rsa.PublicKey:
+		return "RS256"
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return "ES256"
+		}
+	}
+	return ""
+}
+
+// jwk is the subset of RFC 7517 JSON Web Key members used for verification keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// LoadJWKSFile reads a local JWKS document, e.g. a copy of an identity provider's jwks.json
+func LoadJWKSFile(path string) (*JWTKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JWKS document, keys meant for encryption are skipped
+func ParseJWKS(data []byte) (*JWTKeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("going: invalid JWKS: %w", err)
+	}
+	set := &JWTKeySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("going: JWKS key %d (%s): %w", i, k.Kid, err)
+		}
+		alg := inferJWTAlgorithm(key)
+		if k.Alg != "" && k.Alg != alg {
+			// keys for algorithms we do not verify are ignored rather than rejected
+			continue
+		}
+		set.keys = append(set.keys, JWTKey{ID: k.Kid, Algorithm: alg, Key: key})
+	}
+	return set, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) < minHMACKeyLength {
+			return nil, fmt.Errorf("HMAC secret must be at least %d bytes", minHMACKeyLength)
+		}
+		return secret, nil
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,295 @@
+package going
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWTClaimsKey is the Context key the JWT middleware stores the verified claims under
+const JWTClaimsKey = "going/jwtClaims"
+
+var (
+	ErrJWTMissing     = errors.New("going: token is missing")
+	ErrJWTMalformed   = errors.New("going: token is malformed")
+	ErrJWTAlgorithm   = errors.New("going: token algorithm is not allowed")
+	ErrJWTSignature   = errors.New("going: token signature is invalid")
+	ErrJWTExpired     = errors.New("going: token is expired")
+	ErrJWTNotValidYet = errors.New("going: token is not valid yet")
+	ErrJWTIssuer      = errors.New("going: token issuer is invalid")
+	ErrJWTAudience    = errors.New("going: token audience is invalid")
+)
+
+// JWTClaims are the decoded claims of a verified token
+type JWTClaims map[string]any
+
+func (c JWTClaims) str(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+func (c JWTClaims) time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// Subject returns the "sub" claim
+func (c JWTClaims) Subject() string { return c.str("sub") }
+
+// Issuer returns the "iss" claim
+func (c JWTClaims) Issuer() string { return c.str("iss") }
+
+// ExpiresAt returns the "exp" claim, ok is false when the token does not expire
+func (c JWTClaims) ExpiresAt() (t time.Time, ok bool) { return c.time("exp") }
+
+// Audience returns the "aud" claim, which may be a single string or a list
+func (c JWTClaims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		list := make([]string, 0, len(aud))
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// JWTConfig defines the config for the JWT middleware
+type JWTConfig struct {
+	// KeySet holds the verification keys, required
+	KeySet *JWTKeySet
+	// Algorithms that are accepted, HS256, RS256 and ES256 by default
+	Algorithms []string
+	// TokenLookup is a comma separated list of "header:<name>", "cookie:<name>"
+	// or "query:<name>" sources, "header:Authorization" by default
+	TokenLookup string
+	// Issuer is compared with the "iss" claim when set
+	Issuer string
+	// Audience must be listed in the "aud" claim when set
+	Audience string
+	// Leeway tolerates clock skew when checking "exp" and "nbf"
+	Leeway time.Duration
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+type tokenSource struct {
+	kind, name string
+}
+
+// JWT returns a middleware that verifies compact JWS tokens and stores their claims on the Context
+func JWT(config JWTConfig) HandlerFunc {
+	if config.KeySet == nil {
+		panic("going: JWT middleware requires a KeySet")
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = "header:Authorization"
+	}
+	var sources []tokenSource
+	for _, item := range strings.Split(config.TokenLookup, ",") {
+		kind, name, found := strings.Cut(strings.TrimSpace(item), ":")
+		if !found || name == "" || (kind != "header" && kind != "cookie" && kind != "query") {
+			panic("going: invalid JWT token lookup " + item)
+		}
+		sources = append(sources, tokenSource{kind: kind, name: name})
+	}
+
+	return func(c *Context) {
+		token := lookupToken(c, sources)
+		claims, err := ParseJWT(token, config)
+		if err != nil {
+			challenge := `Bearer error="invalid_token", error_description=` + `"` + strings.TrimPrefix(err.Error(), "going: ") + `"`
+			if err == ErrJWTMissing {
+				challenge = "Bearer"
+			}
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.Fail(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		c.Set(JWTClaimsKey, claims)
+		c.Next()
+	}
+}
+
+func lookupToken(c *Context, sources []tokenSource) string {
+	for _, source := range sources {
+		switch source.kind {
+		case "header":
+			value := c.Req.Header.Get(source.name)
+			if strings.EqualFold(source.name, "Authorization") {
+				value, _ = bearerToken(value)
+			}
+			if value != "" {
+				return value
+			}
+		case "cookie":
+			if cookie, err := c.Req.Cookie(source.name); err == nil && cookie.Value != "" {
+				return cookie.Value
+			}
+		case "query":
+			if value := c.Query(source.name); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// JWTClaims returns the claims verified by the JWT middleware, or nil
+func (c *Context) JWTClaims() JWTClaims {
+	if value, ok := c.Get(JWTClaimsKey); ok {
+		claims, _ := value.(JWTClaims)
+		return claims
+	}
+	return nil
+}
+
+// ParseJWT verifies the signature of a compact JWS token and validates its registered claims
+func ParseJWT(token string, config JWTConfig) (JWTClaims, error) {
+	if token == "" {
+		return nil, ErrJWTMissing
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	var header struct {
+		Alg  string   `json:"alg"`
+		Kid  string   `json:"kid"`
+		Crit []string `json:"crit"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	// critical extensions must be understood, and we understand none
+	if len(header.Crit) > 0 {
+		return nil, ErrJWTMalformed
+	}
+	if !jwtAlgorithmAllowed(header.Alg, config.Algorithms) {
+		return nil, ErrJWTAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range config.KeySet.lookup(header.Kid, header.Alg) {
+		if verifyJWTSignature(header.Alg, key.Key, signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrJWTSignature
+	}
+
+	var claims JWTClaims
+	if err := decodeSegment(parts[1], &claims); err != nil || claims == nil {
+		return nil, ErrJWTMalformed
+	}
+	if err := validateJWTClaims(claims, config); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func jwtAlgorithmAllowed(alg string, allowed []string) bool {
+	if len(allowed) == 0 {
+		allowed = []string{"HS256", "RS256", "ES256"}
+	}
+	for _, a := range allowed {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyJWTSignature(alg string, key any, signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch alg {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok || len(secret) < minHMACKeyLength {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		// JWS uses the fixed size R || S encoding rather than ASN.1
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	}
+	return false
+}
+
+func validateJWTClaims(claims JWTClaims, config JWTConfig) error {
+	now := time.Now()
+	if config.Now != nil {
+		now = config.Now()
+	}
+	// a time claim that is present must be a number, skipping its check would
+	// accept a token with "exp": "never" as if it did not expire
+	for _, name := range []string{"exp", "nbf"} {
+		if v, ok := claims[name]; ok {
+			if _, ok := v.(float64); !ok {
+				return ErrJWTMalformed
+			}
+		}
+	}
+	if exp, ok := claims.time("exp"); ok && !now.Before(exp.Add(config.Leeway)) {
+		return ErrJWTExpired
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Add(config.Leeway).Before(nbf) {
+		return ErrJWTNotValidYet
+	}
+	if config.Issuer != "" && claims.Issuer() != config.Issuer {
+		return ErrJWTIssuer
+	}
+	if config.Audience != "" {
+		for _, aud := range claims.Audience() {
+			if aud == config.Audience {
+				return nil
+			}
+		}
+		return ErrJWTAudience
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package going
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func signTestJWT(t *testing.T, alg, kid string, key any, claims JWTClaims) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestParseJWT(t *testing.T) {
+	secret := []byte("tenet-going-jwt-test-secret-0032")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	now := time.Unix(1700000000, 0)
+	config := JWTConfig{
+		KeySet: NewJWTKeySet(
+			JWTKey{ID: "hs", Key: secret},
+			JWTKey{ID: "rs", Key: &rsaKey.PublicKey},
+			JWTKey{ID: "es", Key: &ecKey.PublicKey},
+		),
+		Issuer:   "going",
+		Audience: "api",
+		Leeway:   time.Minute,
+		Now:      func() time.Time { return now },
+	}
+	valid := JWTClaims{"sub": "alice", "iss": "going", "aud": []string{"web", "api"}, "exp": now.Add(time.Hour).Unix()}
+
+	for _, tt := range []struct {
+		alg, kid string
+		key      any
+	}{{"HS256", "hs", secret}, {"RS256", "rs", rsaKey}, {"ES256", "es", ecKey}} {
+		claims, err := ParseJWT(signTestJWT(t, tt.alg, tt.kid, tt.key, valid), config)
+		if err != nil || claims.Subject() != "alice" {
+			t.Fatalf("%s token should verify, got %v", tt.alg, err)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		token string
+		err   error
+	}{
+		"expired":      {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "api", "exp": now.Add(-2 * time.Minute).Unix()}), ErrJWTExpired},
+		"skewed":       {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "api", "exp": now.Add(-30 * time.Second).Unix()}), nil},
+		"not yet":      {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "api", "nbf": now.Add(time.Hour).Unix()}), ErrJWTNotValidYet},
+		"issuer":       {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "evil", "aud": "api"}), ErrJWTIssuer},
+		"audience":     {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "web"}), ErrJWTAudience},
+		"wrong secret": {signTestJWT(t, "HS256", "hs", []byte("other"), valid), ErrJWTSignature},
+		"wrong kid":    {signTestJWT(t, "RS256", "es", rsaKey, valid), ErrJWTSignature},
+		"none":         {signTestJWT(t, "none", "", nil, valid), ErrJWTAlgorithm},
+		"malformed":    {"a.b", ErrJWTMalformed},
+		"string exp":   {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "api", "exp": "never"}), ErrJWTMalformed},
+		"string nbf":   {signTestJWT(t, "HS256", "hs", secret, JWTClaims{"iss": "going", "aud": "api", "nbf": "1700000000"}), ErrJWTMalformed},
+	} {
+		if _, err := ParseJWT(tt.token, config); err != tt.err {
+			t.Fatalf("%s: expected %v, got %v", name, tt.err, err)
+		}
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	enc := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":%q,"e":%q},{"kty":"RSA","kid":"k2","use":"enc","n":%q,"e":%q}]}`,
+		enc(rsaKey.N.Bytes()), enc([]byte{1, 0, 1}), enc(rsaKey.N.Bytes()), enc([]byte{1, 0, 1}))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	os.WriteFile(path, []byte(jwks), 0o600)
+
+	set, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.keys) != 1 || set.keys[0].ID != "k1" || set.keys[0].Algorithm != "RS256" {
+		t.Fatalf("only the signing key should be loaded, got %+v", set.keys)
+	}
+
+	r := New()
+	r.Use(JWT(JWTConfig{KeySet: set, TokenLookup: "header:Authorization,cookie:jwt"}))
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, c.JWTClaims().Subject())
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "jwt", Value: signTestJWT(t, "RS256", "k1", rsaKey, JWTClaims{"sub": "bob"})})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "bob" {
+		t.Fatalf("token from cookie should be accepted, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("missing token should be challenged, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestShortHMACSecretsAreRejected(t *testing.T) {
+	for _, jwks := range []string{`{"keys":[{"kty":"oct","kid":"empty"}]}`, `{"keys":[{"kty":"oct","kid":"short","k":"c2hvcnQ"}]}`} {
+		if _, err := ParseJWKS([]byte(jwks)); err == nil {
+			t.Fatalf("expected %s to be rejected", jwks)
+		}
+	}
+	for _, secret := range [][]byte{{}, []byte("short")} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected NewJWTKeySet to reject a %d byte secret", len(secret))
+				}
+			}()
+			NewJWTKeySet(JWTKey{Key: secret})
+		}()
+	}
+	// a key smuggled past Add still never verifies
+	if verifyJWTSignature("HS256", []byte{}, []byte("a.b"), hmacSHA256(nil, "a.b")) {
+		t.Fatal("an empty secret must not verify signatures")
+	}
+}
+
+func hmacSHA256(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
@@ -12,6 +12,10 @@ func Logger() HandlerFunc {
 		// Process request
 		c.Next()
 		// Calculate resolution time
-		log.Printf("[%d] %s in %v", c.StatusCode, c.Req.RequestURI, time.Since(t))
+		if id := c.RequestID(); id != "" {
+			log.Printf("[%d] %s in %v | %s", c.Writer.Status(), c.Req.RequestURI, time.Since(t), id)
+			return
+		}
+		log.Printf("[%d] %s in %v", c.Writer.Status(), c.Req.RequestURI, time.Since(t))
 	}
 }
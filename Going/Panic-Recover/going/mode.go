@@ -0,0 +1,46 @@
+package going
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// EnvGoingMode is the environment variable the initial mode is read from
+const EnvGoingMode = "GOING_MODE"
+
+const (
+	// DebugMode reloads changed templates and prints extra information
+	DebugMode = "debug"
+	// ReleaseMode caches templates and stays quiet
+	ReleaseMode = "release"
+	// TestMode behaves like release mode, for use in tests
+	TestMode = "test"
+)
+
+var goingMode atomic.Value
+
+func init() {
+	SetMode(os.Getenv(EnvGoingMode))
+}
+
+// SetMode sets the going mode, an empty value selects DebugMode
+func SetMode(value string) {
+	switch value {
+	case "":
+		value = DebugMode
+	case DebugMode, ReleaseMode, TestMode:
+	default:
+		panic("going: unknown mode " + value + " (available modes: debug, release, test)")
+	}
+	goingMode.Store(value)
+}
+
+// Mode returns the current going mode
+func Mode() string {
+	return goingMode.Load().(string)
+}
+
+// IsDebugging reports whether going runs in DebugMode
+func IsDebugging() bool {
+	return Mode() == DebugMode
+}
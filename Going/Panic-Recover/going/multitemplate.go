@@ -0,0 +1,132 @@
+package going
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MultiTemplateConfig describes a template tree where every page is composed
+// with a base layout and shared partials
+type MultiTemplateConfig struct {
+	// Layout is the base layout file, it is the template executed for every page
+	Layout string
+	// Partials are directories whose templates are shared by all pages
+	Partials []string
+	// Pages is the directory holding one file per page, the page name is the path
+	// relative to it without extension, e.g. "users/show"
+	Pages string
+	// Extension of template files, ".tmpl" by default
+	Extension string
+}
+
+// MultiTemplate is an HTMLRender keeping one template set per page, so pages
+// can define the same block names without clashing
+type MultiTemplate struct {
+	mu     sync.RWMutex
+	config MultiTemplateConfig
+	layout string
+	pages  map[string]*templateSet
+	engine *Engine
+}
+
+// LoadHTMLMulti makes Context.HTML render pages composed by a MultiTemplate,
+// it panics like LoadHTMLGlob when a page can not be parsed
+func (engine *Engine) LoadHTMLMulti(config MultiTemplateConfig) *MultiTemplate {
+	if config.Extension == "" {
+		config.Extension = ".tmpl"
+	}
+	m := &MultiTemplate{config: config, layout: filepath.Base(config.Layout), engine: engine}
+	if err := m.scan(); err != nil {
+		panic(err)
+	}
+	engine.HTMLRender = m
+	return m
+}
+
+// Pages returns the names of the loaded pages
+func (m *MultiTemplate) Pages() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.pages))
+	for name := range m.pages {
+		names = append(names, name)
+	}
+	return names
+}
+
+// scan parses a template set for every file of the pages directory
+func (m *MultiTemplate) scan() error {
+	pageFiles, err := findTemplates(m.config.Pages, m.config.Extension)
+	if err != nil {
+		return err
+	}
+	pages := make(map[string]*templateSet, len(pageFiles))
+	for _, file := range pageFiles {
+		rel, err := filepath.Rel(m.config.Pages, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(strings.TrimSuffix(rel, m.config.Extension))
+		page := file
+		set := m.engine.newTemplateSet(func() ([]string, error) {
+			// partials are listed again on reload so new ones are picked up in debug mode
+			files := []string{m.config.Layout}
+			for _, dir := range m.config.Partials {
+				partials, err := findTemplates(dir, m.config.Extension)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, partials...)
+			}
+			return append(files, page), nil
+		})
+		if err := set.load(); err != nil {
+			return fmt.Errorf("going: page %q: %w", name, err)
+		}
+		pages[name] = set
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pages = pages
+	return nil
+}
+
+// Render implements HTMLRender, name is the page name
+func (m *MultiTemplate) Render(w io.Writer, name string, data any, funcs template.FuncMap) error {
+	m.mu.RLock()
+	set, ok := m.pages[name]
+	m.mu.RUnlock()
+	if !ok && IsDebugging() {
+		// a page may have been added since the last scan
+		if err := m.scan(); err != nil {
+			return err
+		}
+		m.mu.RLock()
+		set, ok = m.pages[name]
+		m.mu.RUnlock()
+	}
+	if !ok {
+		return fmt.Errorf("going: html page %q is not defined", name)
+	}
+	return set.Render(w, m.layout, data, funcs)
+}
+
+// findTemplates returns the files below dir having the extension ext
+func findTemplates(dir, ext string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ext) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
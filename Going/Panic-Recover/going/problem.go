@@ -0,0 +1,93 @@
+package going
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details document
+// refer https://www.rfc-editor.org/rfc/rfc7807
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are serialized as additional top-level members
+	Extensions map[string]any
+}
+
+// NewProblem creates a Problem for status, titled with the status text
+func NewProblem(status int, detail string) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if detail != p.Title {
+		p.Detail = detail
+	}
+	return p
+}
+
+// With sets an extension member and returns the Problem for chaining
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Error implements the error interface so a Problem can be passed to Context.Error,
+// the default error handler then responds with the last one, wrapped or not
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// MarshalJSON flattens the extensions next to the standard members,
+// which always win over an extension of the same name
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		doc[key] = value
+	}
+	if p.Type != "" {
+		doc["type"] = p.Type
+	}
+	if p.Title != "" {
+		doc["title"] = p.Title
+	}
+	if p.Status != 0 {
+		doc["status"] = p.Status
+	}
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+	return json.Marshal(doc)
+}
+
+// Problem writes p as application/problem+json, using p.Status as the status code
+func (c *Context) Problem(p *Problem) {
+	code := p.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	c.SetHeader("Content-Type", "application/problem+json")
+	c.Status(code)
+	encoder := json.NewEncoder(c.Writer)
+	if err := encoder.Encode(p); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
+	}
+}
+
+// useProblems reports whether the built-in responses should be problem documents
+func (c *Context) useProblems() bool {
+	return c.engine != nil && c.engine.ProblemDetails
+}
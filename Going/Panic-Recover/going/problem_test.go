@@ -0,0 +1,44 @@
+package going
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemMarshal(t *testing.T) {
+	p := NewProblem(http.StatusForbidden, "no access").With("balance", 30).With("status", 200)
+	data, _ := json.Marshal(p)
+	if string(data) != `{"balance":30,"detail":"no access","status":403,"title":"Forbidden","type":"about:blank"}` {
+		t.Fatalf("unexpected problem document %s", data)
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	r := New()
+	r.ProblemDetails = true
+	r.Use(RecoveryWithWriter(nil))
+	r.GET("/panic", func(c *Context) {
+		panic("oops")
+	})
+
+	for _, tt := range []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/missing", http.StatusNotFound},
+		{"POST", "/panic", http.StatusMethodNotAllowed},
+		{"GET", "/panic", http.StatusInternalServerError},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("%s %s should be a %d problem, got %d %s", tt.method, tt.path, tt.code, w.Code, w.Header().Get("Content-Type"))
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc["status"] != float64(tt.code) {
+			t.Fatalf("unexpected problem document %s", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package going
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm selects how requests are counted
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts up to Limit and refills Limit tokens per Window
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allows Limit requests in any Window, weighting the previous window
+	SlidingWindow
+)
+
+// RateLimitRule describes the limit applied to every key
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the outcome of taking one request from a key's allowance
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the allowance is fully replenished
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed, when denied
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the per-key state, implementations backed by a shared
+// database let several instances enforce one limit
+type RateLimitStore interface {
+	Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitConfig defines the config for the RateLimit middleware
+type RateLimitConfig struct {
+	RateLimitRule
+	// KeyFunc identifies the client, KeyByIP by default
+	KeyFunc func(c *Context) string
+	// Store keeps the counters, an in-memory store by default
+	Store RateLimitStore
+	// LimitHandler answers denied requests, a 429 by default
+	LimitHandler HandlerFunc
+}
+
+// KeyByIP limits requests per client IP
+func KeyByIP(c *Context) string {
+	return c.ClientIP()
+}
+
+// KeyByHeader limits requests per value of header, e.g. an API key,
+// requests without the header are limited per client IP
+func KeyByHeader(header string) func(c *Context) string {
+	return func(c *Context) string {
+		if value := c.Req.Header.Get(header); value != "" {
+			return header + ":" + value
+		}
+		return KeyByIP(c)
+	}
+}
+
+// RateLimit returns a middleware limiting how often each key may call the handlers,
+// it reports the allowance in RateLimit-* headers and Retry-After when denied
+func RateLimit(config RateLimitConfig) HandlerFunc {
+	if config.Limit <= 0 || config.Window <= 0 {
+		panic("going: rate limit needs a positive Limit and Window")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByIP
+	}
+	if config.Store == nil {
+		config.Store = NewRateLimitMemoryStore(0)
+	}
+	if config.LimitHandler == nil {
+		config.LimitHandler = func(c *Context) {
+			c.Fail(http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests))
+		}
+	}
+	return func(c *Context) {
+		result, err := config.Store.Take(config.KeyFunc(c), config.RateLimitRule, time.Now())
+		if err != nil {
+			// fail open, an unavailable store should not take the service down
+			c.Error(err)
+			c.Next()
+			return
+		}
+		c.SetHeader("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.SetHeader("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.SetHeader("RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			c.SetHeader("Retry-After", ceilSeconds(result.RetryAfter))
+			c.Abort()
+			config.LimitHandler(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+type rateLimitEntry struct {
+	// token bucket state
+	tokens float64
+	// sliding window state
+	windowStart   time.Time
+	previousCount int
+	currentCount  int
+
+	last time.Time
+}
+
+// RateLimitMemoryStore is a RateLimitStore for a single instance,
+// keys idle for longer than the idle timeout are evicted
+type RateLimitMemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	idle      time.Duration
+	lastSweep time.Time
+}
+
+// NewRateLimitMemoryStore creates an in-memory store, idle defaults to 10 minutes
+// and should be longer than the rule's Window
+func NewRateLimitMemoryStore(idle time.Duration) *RateLimitMemoryStore {
+	if idle <= 0 {
+		idle = 10 * time.Minute
+	}
+	return &RateLimitMemoryStore{entries: make(map[string]*rateLimitEntry), idle: idle}
+}
+
+// Len returns the number of keys currently tracked
+func (s *RateLimitMemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Take implements RateLimitStore
+func (s *RateLimitMemoryStore) Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{tokens: float64(rule.Limit), windowStart: now, last: now}
+		s.entries[key] = entry
+	}
+	var result RateLimitResult
+	switch rule.Algorithm {
+	case SlidingWindow:
+		result = entry.slidingWindow(rule, now)
+	default:
+		result = entry.tokenBucket(rule, now)
+	}
+	entry.last = now
+	return result, nil
+}
+
+// sweep evicts idle keys, at most once per idle period so Take stays cheap
+func (s *RateLimitMemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idle {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.Sub(entry.last) >= s.idle {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (e *rateLimitEntry) tokenBucket(rule RateLimitRule, now time.Time) RateLimitResult {
+	capacity := float64(rule.Limit)
+	perToken := float64(rule.Window) / capacity
+	e.tokens += float64(now.Sub(e.last)) / perToken
+	if e.tokens > capacity {
+		e.tokens = capacity
+	}
+	result := RateLimitResult{Limit: rule.Limit}
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - e.tokens) * perToken)
+	}
+	result.Remaining = int(e.tokens)
+	result.Reset = time.Duration((capacity - e.tokens) * perToken)
+	return result
+}
+
+func (e *rateLimitEntry) slidingWindow(rule RateLimitRule, now time.Time) RateLimitResult {
+	if elapsed := now.Sub(e.windowStart); elapsed >= rule.Window {
+		windows := elapsed / rule.Window
+		if windows == 1 {
+			e.previousCount = e.currentCount
+		} else {
+			e.previousCount = 0
+		}
+		e.currentCount = 0
+		e.windowStart = e.windowStart.Add(windows * rule.Window)
+	}
+	elapsed := now.Sub(e.windowStart)
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := float64(e.previousCount)*weight + float64(e.currentCount)
+
+	result := RateLimitResult{Limit: rule.Limit}
+	if estimate+1 <= float64(rule.Limit) {
+		e.currentCount++
+		estimate++
+		result.Allowed = true
+	} else if free := float64(rule.Limit - e.currentCount - 1); free >= 0 && e.previousCount > 0 {
+		// wait until the previous window's weight has decayed enough
+		needed := 1 - free/float64(e.previousCount)
+		result.RetryAfter = time.Duration(needed*float64(rule.Window)) - elapsed
+	} else {
+		// the current window becomes the previous one and has to decay as well
+		needed := 1 - float64(rule.Limit-1)/float64(e.currentCount)
+		result.RetryAfter = rule.Window - elapsed + time.Duration(needed*float64(rule.Window))
+	}
+	// requests of the current window keep weighing until the end of the next one
+	result.Reset = rule.Window - elapsed
+	if e.currentCount > 0 {
+		result.Reset += rule.Window
+	}
+	result.Remaining = int(float64(rule.Limit) - estimate)
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	store := NewRateLimitMemoryStore(0)
+	rule := RateLimitRule{Algorithm: TokenBucket, Limit: 2, Window: time.Second}
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 2; i++ {
+		if res, _ := store.Take("k", rule, now); !res.Allowed {
+			t.Fatalf("request %d should be allowed by the burst", i+1)
+		}
+	}
+	res, _ := store.Take("k", rule, now)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("third request should be denied for 500ms, got %+v", res)
+	}
+	if res, _ = store.Take("k", rule, now.Add(500*time.Millisecond)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("a token should be refilled after 500ms, got %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	store := NewRateLimitMemoryStore(0)
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 4, Window: time.Second}
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 4; i++ {
+		store.Take("k", rule, now)
+	}
+	if res, _ := store.Take("k", rule, now); res.Allowed {
+		t.Fatal("fifth request in the window should be denied")
+	}
+	// half way into the next window the previous 4 requests still weigh 2
+	if res, _ := store.Take("k", rule, now.Add(1500*time.Millisecond)); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("request should be allowed with 1 remaining, got %+v", res)
+	}
+}
+
+func TestRateLimitMemoryStoreEviction(t *testing.T) {
+	store := NewRateLimitMemoryStore(time.Minute)
+	rule := RateLimitRule{Limit: 1, Window: time.Second}
+	now := time.Unix(0, 0)
+	store.Take("a", rule, now)
+	store.Take("b", rule, now.Add(time.Minute))
+	if store.Len() != 1 {
+		t.Fatalf("idle key should be evicted, %d keys left", store.Len())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	r := New()
+	r.Use(RateLimit(RateLimitConfig{
+		RateLimitRule: RateLimitRule{Limit: 1, Window: time.Minute},
+		KeyFunc:       KeyByHeader("X-Api-Key"),
+	}))
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	get := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Api-Key", key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := get("a"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("first request should pass, got %d %v", w.Code, w.Header())
+	}
+	if w := get("a"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second request should be limited, got %d %v", w.Code, w.Header())
+	}
+	if w := get("b"); w.Code != http.StatusOK {
+		t.Fatalf("other keys should not be limited, got %d", w.Code)
+	}
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	r := New()
+	r.ForwardedByClientIP = true
+	r.Use(RateLimit(RateLimitConfig{
+		RateLimitRule: RateLimitRule{Limit: 1, Window: time.Minute},
+		KeyFunc:       KeyByIP,
+	}))
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for i, spoofed := range []string{"", "1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		// the proxy appends the address it saw to whatever the client sent
+		req.Header.Set("X-Forwarded-For", strings.TrimPrefix(spoofed+", 203.0.113.7", ", "))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if want := http.StatusTooManyRequests; i > 0 && w.Code != want {
+			t.Fatalf("request %d with spoofed hop %q should be limited, got %d", i, spoofed, w.Code)
+		}
+	}
+}
@@ -1,35 +1,131 @@
 package going
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/http/httputil"
 	"runtime"
 	"strings"
+	"syscall"
 )
 
+// RecoveryFunc defines the function passable to CustomRecovery
+type RecoveryFunc func(c *Context, err any)
+
+// headers whose values must never end up in the logs
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Csrf-Token":        true,
+}
+
 // print stack trace for debug 打印
 func trace(message string) string {
 	var pcs [32]uintptr
 	n := runtime.Callers(3, pcs[:]) //skip first 3 caller 跳过前三个调用者
+	frames := runtime.CallersFrames(pcs[:n])
 	var str strings.Builder
 	str.WriteString(message + "\nTraceback:")
-	for _, pc := range pcs[:n] {
-		fn := runtime.FuncForPC(pc)
-		file, line := fn.FileLine(pc)
-		str.WriteString(fmt.Sprintf("\n\t%s:%d", file, line))
+	for {
+		frame, more := frames.Next()
+		str.WriteString(fmt.Sprintf("\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
 	}
 	return str.String()
 }
 
+// dumpRequest returns the request line and headers with sensitive values redacted,
+// followed by the request id when the RequestID middleware assigned one
+func dumpRequest(c *Context) string {
+	raw, err := httputil.DumpRequest(c.Req, false)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(raw), "\r\n"), "\r\n")
+	for i, line := range lines {
+		name, _, found := strings.Cut(line, ":")
+		if found && sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			lines[i] = name + ": *"
+		}
+	}
+	if id := c.RequestID(); id != "" {
+		lines = append(lines, "Request-Id: "+id)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isBrokenPipe reports whether the panic was caused by the client going away,
+// in which case there is nobody left to send a response to
+func isBrokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET)
+}
+
+func defaultHandleRecovery(c *Context, err any) {
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+	c.Fail(http.StatusInternalServerError, "Internal Server Error")
+}
+
+// Recovery returns a middleware that recovers from any panics and writes a 500 if there was one
 func Recovery() HandlerFunc {
+	return RecoveryWithWriter(log.Writer())
+}
+
+// RecoveryWithWriter returns a Recovery middleware that logs to out
+func RecoveryWithWriter(out io.Writer, recovery ...RecoveryFunc) HandlerFunc {
+	if len(recovery) > 0 {
+		return CustomRecoveryWithWriter(out, recovery[0])
+	}
+	return CustomRecoveryWithWriter(out, defaultHandleRecovery)
+}
+
+// CustomRecovery returns a Recovery middleware that calls handle instead of writing a 500
+func CustomRecovery(handle RecoveryFunc) HandlerFunc {
+	return CustomRecoveryWithWriter(log.Writer(), handle)
+}
+
+// CustomRecoveryWithWriter returns a Recovery middleware that logs to out and calls handle
+func CustomRecoveryWithWriter(out io.Writer, handle RecoveryFunc) HandlerFunc {
+	var logger *log.Logger
+	if out != nil {
+		logger = log.New(out, "", log.LstdFlags)
+	}
 	return func(c *Context) {
 		defer func() {
-			if err := recover(); err != nil {
+			err := recover()
+			if err == nil {
+				return
+			}
+			// let net/http abort the response silently, as it was asked to
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			if isBrokenPipe(err) {
+				if logger != nil {
+					logger.Printf("%s\n%s\n\n", err, dumpRequest(c))
+				}
+				c.Abort()
+				return
+			}
+			if logger != nil {
 				message := fmt.Sprintf("%s", err)
-				log.Printf("%s\n\n", trace(message))
-				c.Fail(http.StatusInternalServerError, "Internal Server Error")
+				logger.Printf("%s\n%s\n\n", dumpRequest(c), trace(message))
 			}
+			handle(c, err)
 		}()
 		c.Next()
 	}
@@ -0,0 +1,83 @@
+package going
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryWithWriter(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(RecoveryWithWriter(&buf))
+	r.GET("/panic", func(c *Context) {
+		panic("oops")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status should be 500, got %d", w.Code)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "oops") || !strings.Contains(out, "going.TestRecoveryWithWriter") {
+		t.Fatalf("trace should contain the panic message and function names, got %s", out)
+	}
+	if strings.Contains(out, "secret") || !strings.Contains(out, "Authorization: *") {
+		t.Fatalf("authorization header should be redacted, got %s", out)
+	}
+}
+
+func TestCustomRecovery(t *testing.T) {
+	r := New()
+	r.Use(CustomRecoveryWithWriter(nil, func(c *Context, err any) {
+		c.String(http.StatusServiceUnavailable, "%v", err)
+	}))
+	r.GET("/panic", func(c *Context) {
+		panic("custom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "custom" {
+		t.Fatalf("custom handler should respond, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	r := New()
+	r.Use(RecoveryWithWriter(nil))
+	r.GET("/abort", func(c *Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Fatalf("http.ErrAbortHandler should be re-panicked, got %v", err)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+}
+
+func TestRecoveryLogsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	r := New()
+	r.Use(RequestID(RequestIDConfig{Generator: func() string { return "req-1" }}), RecoveryWithWriter(&buf))
+	r.GET("/panic", func(c *Context) {
+		panic("oops")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Header().Get("X-Request-ID") != "req-1" {
+		t.Fatalf("request id should be echoed, got %q", w.Header().Get("X-Request-ID"))
+	}
+	if !strings.Contains(buf.String(), "Request-Id: req-1") {
+		t.Fatalf("request id should be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,259 @@
+package going
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNoHTMLRender is recorded by Context.HTML when no templates were loaded
+var ErrNoHTMLRender = errors.New("going: no HTML templates loaded, call LoadHTMLGlob, LoadHTMLFiles, LoadHTMLFS or LoadHTMLMulti")
+
+// bufferPool holds the buffers templates are rendered into before being written
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool, unless one large page would keep it big for good
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > 1<<20 {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// requestTemplateFuncs are template functions whose result depends on the request being
+// rendered. They are declared with these placeholders when templates are parsed, and
+// middlewares bind the real implementation per request with Context.bindTemplateFunc.
+var requestTemplateFuncs = template.FuncMap{
+	"cspNonce":  func() string { return "" },
+	"csrfToken": func() string { return "" },
+	"csrfField": func() template.HTML { return "" },
+}
+
+// HTMLRender renders the named page for Context.HTML. funcs are the template
+// functions bound to the current request, which override the placeholders of
+// the same name the templates were parsed with.
+type HTMLRender interface {
+	Render(w io.Writer, name string, data any, funcs template.FuncMap) error
+}
+
+// templateFuncs returns the functions templates are parsed with, user funcs win
+func (engine *Engine) templateFuncs() template.FuncMap {
+	funcs := make(template.FuncMap, len(requestTemplateFuncs)+len(engine.funcMap))
+	for name, fn := range requestTemplateFuncs {
+		funcs[name] = fn
+	}
+	funcs["asset"] = engine.assetURL
+	funcs["url"] = engine.URL
+	for name, fn := range engine.funcMap {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// templateSet is a parsed set of templates that can be reloaded from its files
+type templateSet struct {
+	mu     sync.RWMutex
+	base   *template.Template // never executed, cloned for request bound funcs
+	shared *template.Template // executed directly when no request funcs are bound
+	mtimes map[string]time.Time
+
+	list  func() ([]string, error)
+	parse func(files []string) (*template.Template, error)
+	stat  func(file string) (fs.FileInfo, error)
+}
+
+// newTemplateSet returns a set parsing the files returned by list with the engine's funcs
+func (engine *Engine) newTemplateSet(list func() ([]string, error)) *templateSet {
+	return &templateSet{
+		list: list,
+		parse: func(files []string) (*template.Template, error) {
+			return template.New("").Funcs(engine.templateFuncs()).ParseFiles(files...)
+		},
+		stat: os.Stat,
+	}
+}
+
+// newTemplateSetFS is newTemplateSet for files of fsys
+func (engine *Engine) newTemplateSetFS(fsys fs.FS, list func() ([]string, error)) *templateSet {
+	return &templateSet{
+		list: list,
+		parse: func(files []string) (*template.Template, error) {
+			// like ParseFiles templates are named by base name, ParseFS would
+			// treat the file names as patterns
+			t := template.New("").Funcs(engine.templateFuncs())
+			for _, file := range files {
+				b, err := fs.ReadFile(fsys, file)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := t.New(path.Base(file)).Parse(string(b)); err != nil {
+					return nil, err
+				}
+			}
+			return t, nil
+		},
+		stat: func(file string) (fs.FileInfo, error) {
+			return fs.Stat(fsys, file)
+		},
+	}
+}
+
+// loadHTML parses the files returned by list, panicking like template.Must on errors
+func (engine *Engine) loadHTML(set *templateSet) {
+	if err := set.load(); err != nil {
+		panic(err)
+	}
+	engine.HTMLRender = set
+}
+
+func (s *templateSet) load() error {
+	files, err := s.list()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("going: no template files to load")
+	}
+	mtimes, err := s.statFiles(files)
+	if err != nil {
+		return err
+	}
+	base, err := s.parse(files)
+	if err != nil {
+		return err
+	}
+	// html/template refuses to Clone a set once it was executed,
+	// so the set that gets executed is a clone and base stays pristine
+	shared, err := base.Clone()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base, s.shared, s.mtimes = base, shared, mtimes
+	return nil
+}
+
+// changed reports whether files were added, removed or modified since the last load
+func (s *templateSet) changed() bool {
+	files, err := s.list()
+	if err != nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(files) != len(s.mtimes) {
+		return true
+	}
+	for _, file := range files {
+		info, err := s.stat(file)
+		if err != nil {
+			return true
+		}
+		if mtime, ok := s.mtimes[file]; !ok || !mtime.Equal(info.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the set to execute, with funcs bound when given.
+// In debug mode the set is re-parsed first if any of its files changed.
+func (s *templateSet) lookup(funcs template.FuncMap) (*template.Template, error) {
+	if IsDebugging() && s.changed() {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.RLock()
+	base, shared := s.base, s.shared
+	s.mu.RUnlock()
+	if len(funcs) == 0 {
+		return shared, nil
+	}
+	clone, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(funcs), nil
+}
+
+// Render implements HTMLRender
+func (s *templateSet) Render(w io.Writer, name string, data any, funcs template.FuncMap) error {
+	tmpl, err := s.lookup(funcs)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+func (s *templateSet) statFiles(files []string) (map[string]time.Time, error) {
+	mtimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := s.stat(file)
+		if err != nil {
+			return nil, err
+		}
+		mtimes[file] = info.ModTime()
+	}
+	return mtimes, nil
+}
+
+// LoadHTMLGlob loads the templates matching pattern, in debug mode the pattern is
+// matched again before each render so added and changed files are picked up
+func (engine *Engine) LoadHTMLGlob(pattern string) {
+	engine.loadHTML(engine.newTemplateSet(func() ([]string, error) {
+		return filepath.Glob(pattern)
+	}))
+}
+
+// LoadHTMLFiles loads the given template files, in debug mode changed files are
+// re-parsed before rendering
+func (engine *Engine) LoadHTMLFiles(files ...string) {
+	engine.loadHTML(engine.newTemplateSet(func() ([]string, error) {
+		return files, nil
+	}))
+}
+
+// LoadHTMLFS loads the templates of fsys, e.g. an embed.FS, matching any of the
+// patterns. Templates are named by base name as with LoadHTMLGlob.
+func (engine *Engine) LoadHTMLFS(fsys fs.FS, patterns ...string) {
+	engine.loadHTML(engine.newTemplateSetFS(fsys, func() ([]string, error) {
+		var files []string
+		seen := make(map[string]bool)
+		for _, pattern := range patterns {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range matches {
+				if !seen[file] {
+					seen[file] = true
+					files = append(files, file)
+				}
+			}
+		}
+		return files, nil
+	}))
+}
+
+// bindTemplateFunc overrides a request template function for this request's renders
+func (c *Context) bindTemplateFunc(name string, fn any) {
+	if c.templateFuncs == nil {
+		c.templateFuncs = make(template.FuncMap)
+	}
+	c.templateFuncs[name] = fn
+}
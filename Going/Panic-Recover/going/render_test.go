@@ -0,0 +1,135 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestTemplateHotReload(t *testing.T) {
+	defer SetMode(Mode())
+	dir := t.TempDir()
+	file := filepath.Join(dir, "page.tmpl")
+	write := func(body string, mtime time.Time) {
+		os.WriteFile(file, []byte(body), 0o644)
+		os.Chtimes(file, mtime, mtime)
+	}
+	write("v1", time.Unix(1, 0))
+
+	r := New()
+	r.LoadHTMLFiles(file)
+	r.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "page.tmpl", nil)
+	})
+	render := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+
+	SetMode(ReleaseMode)
+	write("v2", time.Unix(2, 0))
+	if body := render(); body != "v1" {
+		t.Fatalf("release mode should keep the parsed templates, got %q", body)
+	}
+
+	SetMode(DebugMode)
+	if body := render(); body != "v2" {
+		t.Fatalf("debug mode should reload changed templates, got %q", body)
+	}
+}
+
+func TestMultiTemplate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layouts/base.tmpl":     `<title>{{template "title" .}}</title>{{template "nav" .}}{{template "content" .}}`,
+		"partials/nav.tmpl":     `{{define "nav"}}<nav></nav>{{end}}`,
+		"pages/home.tmpl":       `{{define "title"}}Home{{end}}{{define "content"}}hi {{.}}{{end}}`,
+		"pages/users/show.tmpl": `{{define "title"}}User{{end}}{{define "content"}}user {{.}}{{end}}`,
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte(body), 0o644)
+	}
+
+	r := New()
+	r.LoadHTMLMulti(MultiTemplateConfig{
+		Layout:   filepath.Join(dir, "layouts/base.tmpl"),
+		Partials: []string{filepath.Join(dir, "partials")},
+		Pages:    filepath.Join(dir, "pages"),
+	})
+	r.GET("/:page", func(c *Context) {
+		c.HTML(http.StatusOK, c.Query("name"), c.Param("page"))
+	})
+	render := func(name string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/x?name="+name, nil))
+		return w
+	}
+
+	if body := render("home").Body.String(); body != "<title>Home</title><nav></nav>hi x" {
+		t.Fatalf("unexpected home page %q", body)
+	}
+	if body := render("users/show").Body.String(); body != "<title>User</title><nav></nav>user x" {
+		t.Fatalf("unexpected users/show page %q", body)
+	}
+	if w := render("missing"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 for an unknown page, got %d", w.Code)
+	}
+}
+
+func TestLoadHTMLFS(t *testing.T) {
+	r := New()
+	r.LoadHTMLFS(fstest.MapFS{
+		"templates/index.tmpl":        &fstest.MapFile{Data: []byte(`{{template "header.tmpl"}}{{.}}`)},
+		"templates/parts/header.tmpl": &fstest.MapFile{Data: []byte(`<h1>going</h1>`)},
+	}, "templates/*.tmpl", "templates/parts/*.tmpl")
+	r.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "index.tmpl", "<b>")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if body := w.Body.String(); body != "<h1>going</h1>&lt;b&gt;" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestHTMLRenderErrors(t *testing.T) {
+	var renderErr *Error
+	r := New()
+	r.Use(func(c *Context) {
+		c.Next()
+		renderErr = c.Errors.ByType(ErrorTypeRender).Last()
+	})
+	r.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "page.tmpl", nil)
+	})
+	serve := func() *httptest.ResponseRecorder {
+		renderErr = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	if w := serve(); w.Code != http.StatusInternalServerError || renderErr == nil || renderErr.Err != ErrNoHTMLRender {
+		t.Fatalf("expected a 500 and ErrNoHTMLRender without templates, got %d %v", w.Code, renderErr)
+	}
+
+	r.LoadHTMLFS(fstest.MapFS{
+		"page.tmpl": &fstest.MapFile{Data: []byte(`partial {{index . 1}}`)},
+	}, "*.tmpl")
+	w := serve()
+	if w.Code != http.StatusInternalServerError || renderErr == nil {
+		t.Fatalf("expected a 500 and a render error, got %d %v", w.Code, renderErr)
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Fatalf("partial template output leaked into the response: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package going
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDKey is the Context key the request id is stored under
+const RequestIDKey = "going/requestID"
+
+// RequestIDConfig defines the config for the RequestID middleware
+type RequestIDConfig struct {
+	// Header is read from the request and echoed in the response, X-Request-ID by default
+	Header string
+	// Generator creates an id when the request carries none or an invalid one
+	Generator func() string
+}
+
+// generateRequestID returns 16 random bytes hex encoded
+func generateRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID rejects ids that could break log lines or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns a middleware that reads or generates a correlation id,
+// stores it on the Context and echoes it in the response
+func RequestID(config ...RequestIDConfig) HandlerFunc {
+	var cfg RequestIDConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = "X-Request-ID"
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = generateRequestID
+	}
+	return func(c *Context) {
+		id := c.Req.Header.Get(cfg.Header)
+		if !validRequestID(id) {
+			id = cfg.Generator()
+		}
+		c.Set(RequestIDKey, id)
+		c.SetHeader(cfg.Header, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the id set by the RequestID middleware, or an empty string
+func (c *Context) RequestID() string {
+	return c.GetString(RequestIDKey)
+}
@@ -1,7 +1,10 @@
 package going
 
 import (
+	"fmt"
 	"net/http"
+	"path"
+	"sort"
 	"strings"
 )
 
@@ -83,17 +86,149 @@ func (r *router) getRoutes(method string) []*node {
 	return nodes
 }
 
+// allowedMethods returns the methods that have a route matching path
+func (r *router) allowedMethods(path string) []string {
+	allowed := make([]string, 0)
+	for method := range r.roots {
+		if n, _ := r.lookup(method, path); n != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func notFound(c *Context) {
+	if c.useProblems() {
+		c.Problem(NewProblem(http.StatusNotFound, fmt.Sprintf("no route for %s", c.Path)))
+		return
+	}
+	c.String(http.StatusNotFound, "404 NOT FOUND: %s\n", c.Path)
+}
+
+func methodNotAllowed(allowed []string) HandlerFunc {
+	return func(c *Context) {
+		c.SetHeader("Allow", strings.Join(allowed, ", "))
+		if c.useProblems() {
+			c.Problem(NewProblem(http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed for %s", c.Method, c.Path)))
+			return
+		}
+		c.String(http.StatusMethodNotAllowed, "405 METHOD NOT ALLOWED: %s\n", c.Method)
+	}
+}
+
+// exactMatch reports whether path spells the route pattern exactly. The trie
+// ignores empty segments, so "/users/" and "//users" would otherwise match "/users".
+func exactMatch(pattern, path string) bool {
+	if strings.Contains(pattern, "*") {
+		return true
+	}
+	return !strings.Contains(path, "//") && strings.HasSuffix(pattern, "/") == strings.HasSuffix(path, "/")
+}
+
+// lookup returns the route path matches exactly
+func (r *router) lookup(method, path string) (*node, map[string]string) {
+	n, params := r.getRoute(method, path)
+	if n == nil || !exactMatch(n.pattern, path) {
+		return nil, nil
+	}
+	return n, params
+}
+
+// fixedPath returns the path of a route matching path once it is cleaned and
+// compared case-insensitively
+func (r *router) fixedPath(method, path string) (string, bool) {
+	root, ok := r.roots[method]
+	if !ok {
+		return "", false
+	}
+	n, parts := root.searchFold(parsePattern(cleanPath(path)), 0, nil)
+	if n == nil {
+		return "", false
+	}
+	fixed := "/" + strings.Join(parts, "/")
+	if fixed != "/" && strings.HasSuffix(n.pattern, "/") {
+		fixed += "/"
+	}
+	return fixed, true
+}
+
+// redirectPath returns where to redirect a request matching no route, if anywhere
+func (r *router) redirectPath(c *Context) (string, bool) {
+	engine := c.engine
+	if engine.RedirectTrailingSlash && c.Path != "/" {
+		toggled := c.Path + "/"
+		if strings.HasSuffix(c.Path, "/") {
+			toggled = strings.TrimRight(c.Path, "/")
+		}
+		if n, _ := r.lookup(c.Method, toggled); n != nil {
+			return toggled, true
+		}
+	}
+	if engine.RedirectFixedPath {
+		if fixed, ok := r.fixedPath(c.Method, c.Path); ok && fixed != c.Path {
+			return fixed, true
+		}
+	}
+	return "", false
+}
+
+// redirectTo redirects to path keeping the query, with 301 for GET
+// and HEAD and 308 otherwise so that the method and body are kept
+func redirectTo(path string) HandlerFunc {
+	return func(c *Context) {
+		code := http.StatusPermanentRedirect
+		if c.Method == http.MethodGet || c.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		if c.Req.URL.RawQuery != "" {
+			path += "?" + c.Req.URL.RawQuery
+		}
+		c.Redirect(code, path)
+	}
+}
+
+// cleanPath is path.Clean keeping a trailing slash
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if cleaned != "/" && strings.HasSuffix(p, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// removeExtraSlash collapses repeated slashes
+func removeExtraSlash(p string) string {
+	for strings.Contains(p, "//") {
+		p = strings.ReplaceAll(p, "//", "/")
+	}
+	return p
+}
+
 func (r *router) handle(c *Context) {
-	n, params := r.getRoute(c.Method, c.Path)
+	n, params := r.lookup(c.Method, c.Path)
 
+	// group middlewares are picked with the pattern of the matched route, a path
+	// spelled differently, e.g. "//admin/files/x" for "/admin/files/*filepath",
+	// must not skip them
+	matched := c.Path
+	var handler HandlerFunc
 	if n != nil {
 		key := c.Method + "-" + n.pattern
 		c.Params = params
-		c.handlers = append(c.handlers, r.handlers[key])
+		matched, handler = n.pattern, r.handlers[key]
+	} else if location, ok := r.redirectPath(c); ok {
+		handler = redirectTo(location)
+	} else if allowed := r.allowedMethods(c.Path); len(allowed) > 0 {
+		handler = methodNotAllowed(allowed)
 	} else {
-		c.handlers = append(c.handlers, func(c *Context) {
-			c.String(http.StatusNotFound, "404 NOT FOUND: %s\n", c.Path)
-		})
+		handler = notFound
+	}
+	c.handlers = c.engine.groupMiddlewares(matched)
+	if n != nil {
+		// the declared body length is checked once every BodyLimit ran
+		c.handlers = append(c.handlers, checkDeclaredBody)
 	}
+	c.handlers = append(c.handlers, handler)
 	c.Next()
 }
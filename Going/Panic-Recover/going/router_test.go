@@ -2,6 +2,8 @@ package going
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
@@ -72,3 +74,87 @@ func TestGetRoutes(t *testing.T) {
 		t.Fatal("the number of routes shoule be 4")
 	}
 }
+
+func TestRedirectPaths(t *testing.T) {
+	r := New()
+	r.GET("/users", func(c *Context) { c.String(200, "users") })
+	r.GET("/users/:id/Profile", func(c *Context) { c.String(200, "profile %s", c.Param("id")) })
+	r.GET("/docs/", func(c *Context) { c.String(200, "docs") })
+	r.POST("/login", func(c *Context) { c.String(200, "login") })
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	tests := []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{"GET", "/users", 200, ""},
+		{"GET", "/users/?page=2", 301, "/users?page=2"},
+		{"GET", "/docs", 301, "/docs/"},
+		{"POST", "/login/", 308, "/login"},
+		{"GET", "//users", 404, ""},
+		{"GET", "/USERS", 404, ""},
+	}
+	for _, tt := range tests {
+		w := serve(tt.method, tt.target)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Fatalf("%s %s: got %d %q, want %d %q", tt.method, tt.target, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+
+	r.RedirectFixedPath = true
+	if w := serve("GET", "/a/../USERS/Ab/profile"); w.Code != 301 || w.Header().Get("Location") != "/users/Ab/Profile" {
+		t.Fatalf("expected a redirect to the fixed path, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := serve("GET", "//users"); w.Code != 301 || w.Header().Get("Location") != "/users" {
+		t.Fatalf("expected a redirect without the extra slash, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	r.RedirectFixedPath = false
+	r.RemoveExtraSlash = true
+	if w := serve("GET", "//users//42///Profile"); w.Code != 200 || w.Body.String() != "profile 42" {
+		t.Fatalf("expected the extra slashes to be ignored, got %d %q", w.Code, w.Body.String())
+	}
+
+	r.RedirectTrailingSlash = false
+	if w := serve("GET", "/users/"); w.Code != 404 {
+		t.Fatalf("expected 404 without trailing slash redirects, got %d", w.Code)
+	}
+}
+
+func TestContextRedirect(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a status code that is not a redirect")
+		}
+	}()
+	c.Redirect(http.StatusOK, "/")
+}
+
+func TestRemoveExtraSlashRunsGroupMiddlewares(t *testing.T) {
+	for _, remove := range []bool{true, false} {
+		r := New()
+		r.RemoveExtraSlash = remove
+		api := r.Group("/api")
+		api.Use(BasicAuth(Accounts{"admin": "secret"}))
+		api.GET("/admin", func(c *Context) { c.String(200, "protected") })
+		api.GET("/files/*filepath", func(c *Context) { c.String(200, "protected") })
+
+		targets := []string{"/api/files/x", "//api/files/x", "/api//files/x", "/api/files//x"}
+		if remove {
+			targets = append(targets, "/api/admin", "//api/admin", "//api//admin")
+		}
+		for _, target := range targets {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("RemoveExtraSlash=%v %s: expected the group auth to run, got %d %q", remove, target, w.Code, w.Body.String())
+			}
+		}
+	}
+}
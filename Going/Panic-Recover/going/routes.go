@@ -0,0 +1,126 @@
+package going
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Routes returns the registered routes in registration order
+func (engine *Engine) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(engine.routes))
+	for _, route := range engine.routes {
+		info := *route
+		info.Middlewares = engine.middlewareCount(route.Path)
+		routes = append(routes, info)
+	}
+	return routes
+}
+
+// addRouteInfo records a route, replacing one registered before for the same method and pattern
+func (engine *Engine) addRouteInfo(route *RouteInfo) {
+	for i, other := range engine.routes {
+		if other.Method == route.Method && other.Path == route.Path {
+			engine.routes[i] = route
+			return
+		}
+	}
+	engine.routes = append(engine.routes, route)
+}
+
+// hasRoute reports whether a route is registered for method on the trie node of
+// pattern, which "/docs" and "/docs/" share
+func (engine *Engine) hasRoute(method, pattern string) bool {
+	parts := strings.Join(parsePattern(pattern), "/")
+	for _, route := range engine.routes {
+		if route.Method == method && strings.Join(parsePattern(route.Path), "/") == parts {
+			return true
+		}
+	}
+	return false
+}
+
+// middlewareCount counts the middlewares ServeHTTP runs for the pattern
+func (engine *Engine) middlewareCount(pattern string) int {
+	return len(engine.groupMiddlewares(pattern))
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method string
+	// Path is the full pattern, including the prefix of the group
+	Path string
+	// Handler is the name of the handler function
+	Handler string
+	// Middlewares is the number of group middlewares run before the handler
+	Middlewares int
+
+	name   string
+	engine *Engine
+}
+
+// Name names the route so Engine.URL and the url template function can build
+// URLs to it. It panics when the name is already taken by another route.
+func (r *RouteInfo) Name(name string) *RouteInfo {
+	engine := r.engine
+	if other, ok := engine.namedRoutes[name]; ok && other != r {
+		panic(fmt.Sprintf("going: route name %q is already used by %s %s", name, other.Method, other.Path))
+	}
+	if engine.namedRoutes == nil {
+		engine.namedRoutes = make(map[string]*RouteInfo)
+	}
+	delete(engine.namedRoutes, r.name)
+	r.name = name
+	engine.namedRoutes[name] = r
+	return r
+}
+
+// URL builds the path of the route called name, filling its :param and *catchall
+// segments in order with params. Templates can call it as {{url "user.show" .ID}}.
+func (engine *Engine) URL(name string, params ...any) (string, error) {
+	route, ok := engine.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("going: no route named %q", name)
+	}
+	parts := parsePattern(route.Path)
+	segments := make([]string, 0, len(parts))
+	used := 0
+	for _, part := range parts {
+		switch part[0] {
+		case ':', '*':
+			if used == len(params) {
+				return "", fmt.Errorf("going: route %q needs a value for %s", name, part)
+			}
+			value := fmt.Sprint(params[used])
+			used++
+			if part[0] == ':' {
+				segments = append(segments, url.PathEscape(value))
+				continue
+			}
+			// a catch-all spans segments, each is escaped but the slashes are kept
+			values := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for i := range values {
+				values[i] = url.PathEscape(values[i])
+			}
+			segments = append(segments, strings.Join(values, "/"))
+		default:
+			segments = append(segments, part)
+		}
+	}
+	if used != len(params) {
+		return "", fmt.Errorf("going: route %q takes %d params, got %d", name, used, len(params))
+	}
+	u := "/" + strings.Join(segments, "/")
+	// the router redirects a path without the trailing slash of its route,
+	// a catch-all value brings its own
+	if strings.HasSuffix(route.Path, "/") && !strings.Contains(route.Path, "*") && u != "/" {
+		u += "/"
+	}
+	return u, nil
+}
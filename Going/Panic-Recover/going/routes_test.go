@@ -0,0 +1,85 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNamedRoutes(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.GET("/users/:id", func(c *Context) {}).Name("user.show")
+	r.GET("/files/*filepath", func(c *Context) {}).Name("files")
+	r.GET("/docs/", func(c *Context) {}).Name("docs")
+	api.GET("/users/:id/posts/", func(c *Context) {}).Name("user.posts")
+	r.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "page.tmpl", 42)
+	})
+	r.LoadHTMLFS(fstest.MapFS{
+		"page.tmpl": &fstest.MapFile{Data: []byte(`<a href="{{url "user.show" .}}">`)},
+	}, "*.tmpl")
+
+	tests := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"user.show", []any{42}, "/api/users/42"},
+		{"user.show", []any{"a b/c"}, "/api/users/a%20b%2Fc"},
+		{"files", []any{"css/app main.css"}, "/files/css/app%20main.css"},
+		{"docs", nil, "/docs/"},
+		{"user.posts", []any{42}, "/api/users/42/posts/"},
+	}
+	for _, tt := range tests {
+		if got, err := r.URL(tt.name, tt.params...); err != nil || got != tt.want {
+			t.Fatalf("URL(%q, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+	if _, err := r.URL("user.show"); err == nil {
+		t.Fatal("expected an error for a missing param")
+	}
+	if _, err := r.URL("user.show", 1, 2); err == nil {
+		t.Fatal("expected an error for an extra param")
+	}
+	if _, err := r.URL("missing"); err == nil {
+		t.Fatal("expected an error for an unknown route")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if body := w.Body.String(); body != `<a href="/api/users/42">` {
+		t.Fatalf("unexpected page %q", body)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a duplicate route name")
+		}
+	}()
+	r.GET("/other", func(c *Context) {}).Name("files")
+}
+
+func listUsers(c *Context) {}
+
+func TestRoutes(t *testing.T) {
+	r := New()
+	r.Use(Logger())
+	api := r.Group("/api")
+	api.Use(Recovery())
+	api.GET("/users", listUsers)
+	r.POST("/login", func(c *Context) {})
+
+	routes := r.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+	want := RouteInfo{Method: "GET", Path: "/api/users", Handler: "going.listUsers", Middlewares: 2}
+	if got := routes[0]; got.Method != want.Method || got.Path != want.Path || got.Handler != want.Handler || got.Middlewares != want.Middlewares {
+		t.Fatalf("unexpected route %+v", got)
+	}
+	if got := routes[1]; got.Method != "POST" || got.Path != "/login" || got.Middlewares != 1 {
+		t.Fatalf("unexpected route %+v", got)
+	}
+}
@@ -0,0 +1,148 @@
+package going
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CSPNonceKey is the Context key the per-request Content-Security-Policy nonce is stored under
+const CSPNonceKey = "going/cspNonce"
+
+// SecureConfig defines the config for the Secure middleware, empty fields send no header
+type SecureConfig struct {
+	// AllowedHosts rejects requests for any other Host with 400, empty allows all
+	AllowedHosts []string
+	// SSLRedirect redirects plain HTTP requests to HTTPS
+	SSLRedirect bool
+	// SSLHost is the host to redirect to, the request host by default
+	SSLHost string
+	// SSLProxyHeaders mark a request as HTTPS when terminated by a proxy,
+	// e.g. {"X-Forwarded-Proto": "https"}
+	SSLProxyHeaders map[string]string
+	// STSSeconds is the max-age of Strict-Transport-Security, only sent over HTTPS
+	STSSeconds           int64
+	STSIncludeSubdomains bool
+	STSPreload           bool
+	// ContentTypeNosniff sends X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool
+	// FrameOptions is the X-Frame-Options value, e.g. DENY or SAMEORIGIN
+	FrameOptions      string
+	ReferrerPolicy    string
+	PermissionsPolicy string
+	// ContentSecurityPolicy may contain {nonce}, which is replaced by a fresh nonce per
+	// request, available to templates as {{cspNonce}} and to handlers as Context.CSPNonce
+	ContentSecurityPolicy string
+}
+
+// DefaultSecureConfig returns a strict config suitable for most HTML applications
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		STSSeconds:            31536000,
+		STSIncludeSubdomains:  true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'; object-src 'none'; base-uri 'self'",
+	}
+}
+
+// Secure returns a middleware setting the usual security headers in one place
+func Secure(config SecureConfig) HandlerFunc {
+	sts := ""
+	if config.STSSeconds > 0 {
+		sts = "max-age=" + strconv.FormatInt(config.STSSeconds, 10)
+		if config.STSIncludeSubdomains {
+			sts += "; includeSubDomains"
+		}
+		if config.STSPreload {
+			sts += "; preload"
+		}
+	}
+	useNonce := strings.Contains(config.ContentSecurityPolicy, "{nonce}")
+
+	return func(c *Context) {
+		if len(config.AllowedHosts) > 0 && !hostAllowed(config.AllowedHosts, c.Req.Host) {
+			c.Fail(http.StatusBadRequest, "host not allowed")
+			return
+		}
+		https := isHTTPS(c.Req, config.SSLProxyHeaders)
+		if config.SSLRedirect && !https {
+			host := config.SSLHost
+			if host == "" {
+				host = c.Req.Host
+			}
+			code := http.StatusMovedPermanently
+			if c.Method != http.MethodGet && c.Method != http.MethodHead {
+				code = http.StatusPermanentRedirect
+			}
+			c.SetHeader("Location", "https://"+host+c.Req.URL.RequestURI())
+			c.Abort()
+			c.Status(code)
+			return
+		}
+
+		header := c.Writer.Header()
+		if sts != "" && https {
+			header.Set("Strict-Transport-Security", sts)
+		}
+		if config.ContentTypeNosniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+		if config.FrameOptions != "" {
+			header.Set("X-Frame-Options", config.FrameOptions)
+		}
+		if config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", config.ReferrerPolicy)
+		}
+		if config.PermissionsPolicy != "" {
+			header.Set("Permissions-Policy", config.PermissionsPolicy)
+		}
+		if csp := config.ContentSecurityPolicy; csp != "" {
+			if useNonce {
+				nonce := newCSPNonce()
+				csp = strings.ReplaceAll(csp, "{nonce}", nonce)
+				c.Set(CSPNonceKey, nonce)
+				c.bindTemplateFunc("cspNonce", func() string { return nonce })
+			}
+			header.Set("Content-Security-Policy", csp)
+		}
+		c.Next()
+	}
+}
+
+// CSPNonce returns the nonce the Secure middleware put into the Content-Security-Policy
+func (c *Context) CSPNonce() string {
+	return c.GetString(CSPNonceKey)
+}
+
+func newCSPNonce() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+func isHTTPS(req *http.Request, proxyHeaders map[string]string) bool {
+	if req.TLS != nil {
+		return true
+	}
+	for name, value := range proxyHeaders {
+		if strings.EqualFold(req.Header.Get(name), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostAllowed(allowed []string, host string) bool {
+	for _, h := range allowed {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
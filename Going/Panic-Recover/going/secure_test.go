@@ -0,0 +1,52 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecure(t *testing.T) {
+	r := New()
+	r.Use(Secure(SecureConfig{
+		AllowedHosts:          []string{"example.com"},
+		SSLRedirect:           true,
+		SSLProxyHeaders:       map[string]string{"X-Forwarded-Proto": "https"},
+		STSSeconds:            60,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	}))
+	r.LoadHTMLGlob("testdata/*.tmpl")
+	r.GET("/page", func(c *Context) {
+		c.HTML(http.StatusOK, "nonce.tmpl", "run()")
+	})
+
+	req := httptest.NewRequest("GET", "http://example.com/page?a=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "https://example.com/page?a=1" {
+		t.Fatalf("plain HTTP should be redirected, got %d %v", w.Code, w.Header())
+	}
+
+	req = httptest.NewRequest("GET", "http://evil.com/page", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown host should be rejected, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "http://example.com/page", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	h := w.Header()
+	if h.Get("Strict-Transport-Security") != "max-age=60" || h.Get("X-Frame-Options") != "DENY" || h.Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("security headers should be set, got %v", h)
+	}
+	nonce := strings.TrimSuffix(strings.TrimPrefix(h.Get("Content-Security-Policy"), "script-src 'nonce-"), "'")
+	if nonce == "" || w.Body.String() != `<script nonce="`+nonce+`"></script><p>run()</p>` {
+		t.Fatalf("template should render the request's nonce %q, got %s", nonce, w.Body.String())
+	}
+}
@@ -0,0 +1,253 @@
+package going
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SessionCookieStore keeps the whole session in the cookie, signed and encrypted.
+// Values round-trip through JSON, so numbers come back as float64. The expiry is
+// signed with the values, but a copy of the cookie stays valid until then, Destroy
+// can not revoke it.
+type SessionCookieStore struct {
+	codec *SecureCookie
+}
+
+type cookieSession struct {
+	ID      string         `json:"id"`
+	Values  map[string]any `json:"values"`
+	Expires int64          `json:"expires"`
+}
+
+// NewSessionCookieStore creates a store from hash key and block key pairs, see NewSecureCookie.
+// A block key should be given, session values are otherwise readable by the client.
+func NewSessionCookieStore(keyPairs ...[]byte) *SessionCookieStore {
+	return &SessionCookieStore{codec: NewSecureCookie(keyPairs...).MaxAge(0)}
+}
+
+// Load implements SessionStore
+func (s *SessionCookieStore) Load(cookie string) (string, map[string]any, error) {
+	value, err := s.codec.Decode("session", cookie)
+	if err != nil {
+		return "", nil, ErrSessionNotFound
+	}
+	var data cookieSession
+	if err := json.Unmarshal([]byte(value), &data); err != nil || data.ID == "" {
+		return "", nil, ErrSessionNotFound
+	}
+	if time.Now().Unix() >= data.Expires {
+		return "", nil, ErrSessionNotFound
+	}
+	if data.Values == nil {
+		data.Values = make(map[string]any)
+	}
+	return data.ID, data.Values, nil
+}
+
+// Save implements SessionStore
+func (s *SessionCookieStore) Save(id string, values map[string]any, maxAge time.Duration) (string, error) {
+	data, err := json.Marshal(cookieSession{ID: id, Values: values, Expires: time.Now().Add(maxAge).Unix()})
+	if err != nil {
+		return "", err
+	}
+	return s.codec.Encode("session", string(data))
+}
+
+// Delete implements SessionStore, there is nothing to delete on the server
+func (s *SessionCookieStore) Delete(id string) error {
+	return nil
+}
+
+type memorySession struct {
+	values  map[string]any
+	expires time.Time
+}
+
+// SessionMemoryStore keeps sessions in process until their TTL expires
+type SessionMemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	lastSweep time.Time
+}
+
+// NewSessionMemoryStore creates a store for a single instance, sessions are lost on restart
+func NewSessionMemoryStore() *SessionMemoryStore {
+	return &SessionMemoryStore{sessions: make(map[string]memorySession)}
+}
+
+// Load implements SessionStore
+func (s *SessionMemoryStore) Load(cookie string) (string, map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[cookie]
+	if !ok || time.Now().After(session.expires) {
+		return "", nil, ErrSessionNotFound
+	}
+	return cookie, copyValues(session.values), nil
+}
+
+// Save implements SessionStore
+func (s *SessionMemoryStore) Save(id string, values map[string]any, maxAge time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= time.Minute {
+		s.lastSweep = now
+		for key, session := range s.sessions {
+			if now.After(session.expires) {
+				delete(s.sessions, key)
+			}
+		}
+	}
+	s.sessions[id] = memorySession{values: copyValues(values), expires: now.Add(maxAge)}
+	return id, nil
+}
+
+// Delete implements SessionStore
+func (s *SessionMemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+func copyValues(values map[string]any) map[string]any {
+	cp := make(map[string]any, len(values))
+	for k, v := range values {
+		cp[k] = v
+	}
+	return cp
+}
+
+// SessionFilesystemStore keeps each session as a JSON file in a directory.
+// Values round-trip through JSON, so numbers come back as float64.
+type SessionFilesystemStore struct {
+	dir       string
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+type fileSession struct {
+	Values  map[string]any `json:"values"`
+	Expires time.Time      `json:"expires"`
+}
+
+// NewSessionFilesystemStore creates a store in dir, which is created if needed
+func NewSessionFilesystemStore(dir string) (*SessionFilesystemStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &SessionFilesystemStore{dir: dir}, nil
+}
+
+// path returns the file of a session, ids are random tokens so anything else is rejected
+func (s *SessionFilesystemStore) path(id string) (string, error) {
+	if len(id) == 0 || len(id) > 128 {
+		return "", ErrSessionNotFound
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", ErrSessionNotFound
+		}
+	}
+	return filepath.Join(s.dir, "session_"+id+".json"), nil
+}
+
+// Load implements SessionStore
+func (s *SessionFilesystemStore) Load(cookie string) (string, map[string]any, error) {
+	path, err := s.path(cookie)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	var session fileSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return "", nil, err
+	}
+	if time.Now().After(session.Expires) {
+		os.Remove(path)
+		return "", nil, ErrSessionNotFound
+	}
+	if session.Values == nil {
+		session.Values = make(map[string]any)
+	}
+	return cookie, session.Values, nil
+}
+
+// Save implements SessionStore, the file is replaced atomically
+func (s *SessionFilesystemStore) Save(id string, values map[string]any, maxAge time.Duration) (string, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	s.mu.Lock()
+	sweep := now.Sub(s.lastSweep) >= time.Minute
+	if sweep {
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+	if sweep {
+		s.sweep(now)
+	}
+	data, err := json.Marshal(fileSession{Values: values, Expires: now.Add(maxAge)})
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(s.dir, "session_*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return id, nil
+}
+
+// sweep removes the files of expired sessions, which Load only removes when
+// their cookie comes back
+func (s *SessionFilesystemStore) sweep(now time.Time) {
+	paths, _ := filepath.Glob(filepath.Join(s.dir, "session_*.json"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var session fileSession
+		if err := json.Unmarshal(data, &session); err == nil && now.After(session.Expires) {
+			os.Remove(path)
+		}
+	}
+}
+
+// Delete implements SessionStore
+func (s *SessionFilesystemStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
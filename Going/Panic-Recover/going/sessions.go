@@ -0,0 +1,211 @@
+package going
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// sessionKey is the Context key the session manager is stored under
+const sessionKey = "going/session"
+
+const flashKey = "_flash"
+
+// ErrSessionNotFound is returned by a SessionStore for unknown or expired sessions
+var ErrSessionNotFound = errors.New("going: session not found")
+
+// SessionStore persists session values
+type SessionStore interface {
+	// Load returns the id and values of the session a cookie refers to
+	Load(cookie string) (id string, values map[string]any, err error)
+	// Save stores the values under id and returns the cookie value to send
+	Save(id string, values map[string]any, maxAge time.Duration) (cookie string, err error)
+	// Delete removes the session with id
+	Delete(id string) error
+}
+
+// SessionOptions configures the session cookie, zero Path, MaxAge and SameSite
+// take the value of DefaultSessionOptions. HttpOnly is used as given, start from
+// DefaultSessionOptions to keep it.
+type SessionOptions struct {
+	Path   string
+	Domain string
+	// MaxAge is the lifetime of the cookie and of the stored session
+	MaxAge   time.Duration
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// DefaultSessionOptions returns options for a one day, HTTP only, SameSite=Lax cookie
+func DefaultSessionOptions() SessionOptions {
+	return SessionOptions{Path: "/", MaxAge: 24 * time.Hour, HttpOnly: true, SameSite: http.SameSiteLaxMode}
+}
+
+func (opts SessionOptions) withDefaults() SessionOptions {
+	defaults := DefaultSessionOptions()
+	if opts.MaxAge < 0 {
+		panic("going: session MaxAge can not be negative")
+	}
+	if opts.Path == "" {
+		opts.Path = defaults.Path
+	}
+	if opts.MaxAge == 0 {
+		opts.MaxAge = defaults.MaxAge
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = defaults.SameSite
+	}
+	return opts
+}
+
+type sessionManager struct {
+	name    string
+	store   SessionStore
+	options SessionOptions
+	session *Session
+}
+
+// Sessions returns a middleware making the session stored in the cookie called name
+// available through Context.Session, changes are persisted by Session.Save
+func Sessions(name string, store SessionStore, options ...SessionOptions) HandlerFunc {
+	opts := DefaultSessionOptions()
+	if len(options) > 0 {
+		opts = options[0].withDefaults()
+	}
+	return func(c *Context) {
+		c.Set(sessionKey, &sessionManager{name: name, store: store, options: opts})
+		c.Next()
+	}
+}
+
+// Session returns the current session, loading it on first use.
+// It panics when the Sessions middleware is not installed.
+func (c *Context) Session() *Session {
+	value, ok := c.Get(sessionKey)
+	if !ok {
+		panic("going: Sessions middleware is not installed")
+	}
+	m := value.(*sessionManager)
+	if m.session == nil {
+		m.session = m.load(c)
+	}
+	return m.session
+}
+
+func (m *sessionManager) load(c *Context) *Session {
+	s := &Session{manager: m, c: c}
+	if cookie, err := c.Req.Cookie(m.name); err == nil && cookie.Value != "" {
+		id, values, err := m.store.Load(cookie.Value)
+		if err == nil {
+			s.ID, s.values = id, values
+			return s
+		}
+		if err != ErrSessionNotFound {
+			c.Error(err)
+		}
+	}
+	s.ID = randomToken(32)
+	s.values = make(map[string]any)
+	s.isNew = true
+	return s
+}
+
+// Session holds the values of one client's session
+type Session struct {
+	ID      string
+	values  map[string]any
+	isNew   bool
+	oldIDs  []string
+	manager *sessionManager
+	c       *Context
+}
+
+// IsNew reports whether the session was created by this request
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns the value stored under key
+func (s *Session) Get(key string) any {
+	return s.values[key]
+}
+
+// Set stores value under key
+func (s *Session) Set(key string, value any) {
+	s.values[key] = value
+}
+
+// Delete removes key from the session
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+}
+
+// Clear removes all values from the session
+func (s *Session) Clear() {
+	s.values = make(map[string]any)
+}
+
+// Flash adds a value that is removed from the session once read with Flashes
+func (s *Session) Flash(value any) {
+	flashes, _ := s.values[flashKey].([]any)
+	s.values[flashKey] = append(flashes, value)
+}
+
+// Flashes returns and removes the flash values, the session has to be saved afterwards
+func (s *Session) Flashes() []any {
+	flashes, _ := s.values[flashKey].([]any)
+	delete(s.values, flashKey)
+	return flashes
+}
+
+// Regenerate moves the session to a new ID, call it whenever the privilege level
+// changes, e.g. on login, to prevent session fixation. The old ID is deleted on Save.
+func (s *Session) Regenerate() {
+	if !s.isNew {
+		s.oldIDs = append(s.oldIDs, s.ID)
+	}
+	s.ID = randomToken(32)
+}
+
+// Save persists the session and sets its cookie, it must be called before the body is written
+func (s *Session) Save() error {
+	m := s.manager
+	for _, id := range s.oldIDs {
+		if err := m.store.Delete(id); err != nil {
+			return err
+		}
+	}
+	s.oldIDs = nil
+	cookie, err := m.store.Save(s.ID, s.values, m.options.MaxAge)
+	if err != nil {
+		return err
+	}
+	s.setCookie(cookie, int(m.options.MaxAge/time.Second))
+	s.isNew = false
+	return nil
+}
+
+// Destroy deletes the session from the store and expires its cookie
+func (s *Session) Destroy() error {
+	if err := s.manager.store.Delete(s.ID); err != nil {
+		return err
+	}
+	s.values = make(map[string]any)
+	s.setCookie("", -1)
+	return nil
+}
+
+func (s *Session) setCookie(value string, maxAge int) {
+	opts := s.manager.options
+	http.SetCookie(s.c.Writer, &http.Cookie{
+		Name:     s.manager.name,
+		Value:    value,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   opts.Secure,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	})
+}
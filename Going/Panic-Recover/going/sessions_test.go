@@ -0,0 +1,152 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testSessions(t *testing.T, store SessionStore) {
+	r := New()
+	r.Use(Sessions("sid", store))
+	r.GET("/login", func(c *Context) {
+		s := c.Session()
+		s.Regenerate()
+		s.Set("user", "alice")
+		s.Flash("welcome")
+		if err := s.Save(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	r.GET("/me", func(c *Context) {
+		s := c.Session()
+		flashes := s.Flashes()
+		s.Save()
+		c.String(http.StatusOK, "%v %v", s.Get("user"), flashes)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+	cookie := w.Result().Cookies()[0]
+
+	get := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w = get(cookie)
+	if w.Body.String() != "alice [welcome]" {
+		t.Fatalf("session should be loaded with its flash, got %q", w.Body.String())
+	}
+	if w = get(w.Result().Cookies()[0]); w.Body.String() != "alice []" {
+		t.Fatalf("flashes should only be read once, got %q", w.Body.String())
+	}
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	testSessions(t, NewSessionCookieStore([]byte("tenet-going-cookie-hash-key-0032"), []byte("0123456789abcdef")))
+}
+
+func TestSessionMemoryStore(t *testing.T) {
+	testSessions(t, NewSessionMemoryStore())
+}
+
+func TestSessionFilesystemStore(t *testing.T) {
+	store, err := NewSessionFilesystemStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSessions(t, store)
+	if _, _, err := store.Load("../../etc/passwd"); err != ErrSessionNotFound {
+		t.Fatalf("path traversal should be rejected, got %v", err)
+	}
+}
+
+func TestSessionFilesystemStoreSweep(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSessionFilesystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Save("expired", map[string]any{"user": "alice"}, -time.Second)
+	store.lastSweep = time.Time{}
+	store.Save("live", map[string]any{"user": "bob"}, time.Hour)
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "session_*.json"))
+	if len(paths) != 1 || filepath.Base(paths[0]) != "session_live.json" {
+		t.Fatalf("expired sessions should be swept on save, got %v", paths)
+	}
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	store := NewSessionMemoryStore()
+	store.Save("fixed", map[string]any{"role": "guest"}, time.Minute)
+
+	r := New()
+	r.Use(Sessions("sid", store))
+	r.GET("/login", func(c *Context) {
+		s := c.Session()
+		s.Regenerate()
+		s.Set("role", "admin")
+		s.Save()
+		c.String(http.StatusOK, s.ID)
+	})
+	req := httptest.NewRequest("GET", "/login", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "fixed"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() == "fixed" {
+		t.Fatal("session ID should change on Regenerate")
+	}
+	if _, _, err := store.Load("fixed"); err != ErrSessionNotFound {
+		t.Fatal("old session ID should be deleted")
+	}
+}
+
+func TestSessionCookieStoreExpires(t *testing.T) {
+	store := NewSessionCookieStore([]byte("tenet-going-cookie-hash-key-0032"), []byte("0123456789abcdef"))
+	values := map[string]any{"user": "x"}
+	cookie, err := store.Save("id", values, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, loaded, err := store.Load(cookie); err != nil || loaded["user"] != "x" {
+		t.Fatalf("expected the session to load, got %v %v", loaded, err)
+	}
+	expired, _ := store.Save("id", values, -time.Second)
+	if _, _, err := store.Load(expired); err != ErrSessionNotFound {
+		t.Fatalf("expected an expired session to be rejected, got %v", err)
+	}
+}
+
+func TestSessionOptionsDefaults(t *testing.T) {
+	r := New()
+	r.Use(Sessions("sid", NewSessionMemoryStore(), SessionOptions{Secure: true}))
+	r.GET("/login", func(c *Context) {
+		c.Session().Set("user", "alice")
+		c.Session().Save()
+	})
+	r.GET("/me", func(c *Context) {
+		c.String(http.StatusOK, "%v", c.Session().Get("user"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+	cookie := w.Result().Cookies()[0]
+	if cookie.Path != "/" || cookie.MaxAge != int((24*time.Hour)/time.Second) || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("unset options should take their defaults, got %+v", cookie)
+	}
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "alice" {
+		t.Fatalf("session should be loaded, got %q", w.Body.String())
+	}
+}
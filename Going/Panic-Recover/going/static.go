@@ -0,0 +1,311 @@
+package going
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StaticConfig defines the config for StaticWithConfig
+type StaticConfig struct {
+	// Root is the file system files are served from
+	Root fs.FS
+	// Browse lists the entries of directories without an index file
+	Browse bool
+	// Index is served for directory requests, "index.html" by default
+	Index string
+	// SPA serves the Index of Root for paths that match no file, so client side
+	// routes of single page applications can be reloaded
+	SPA bool
+	// CacheControl maps file extensions such as ".css" to a Cache-Control value,
+	// the "*" entry applies to every other file
+	CacheControl map[string]string
+	// Precompressed serves the ".gz" sibling of a file, when there is one,
+	// to clients accepting gzip
+	Precompressed bool
+	// Fingerprint hashes every file at startup and serves it as well under a name
+	// containing the hash, e.g. "css/app.3f2a1b9c0d4e5f60.css", with immutable
+	// caching. Templates resolve these names with {{asset "css/app.css"}}, so the
+	// fingerprinted roots of an engine must not contain the same file name.
+	Fingerprint bool
+}
+
+// immutableCacheControl is sent for fingerprinted files, whose content never changes
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// StaticWithConfig serves the files of config.Root under relativePath. Besides
+// "<relativePath>/*filepath" it registers "GET <relativePath>/" for the root
+// directory, unless a GET route already takes that path, so Static("/", dir)
+// keeps a "GET /" handler registered before it. A route registered afterwards
+// replaces the root directory route.
+func (group *RouterGroup) StaticWithConfig(relativePath string, config StaticConfig) {
+	if config.Root == nil {
+		panic("going: static file system is nil")
+	}
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	handler := group.createStaticHandler(path.Join(group.prefix, relativePath), config)
+	group.GET(path.Join(relativePath, "/*filepath"), handler)
+	// catch-all parameters never match an empty path, the root directory is
+	// registered on its own, with the trailing slash directories are served with
+	root := strings.TrimSuffix(path.Join(relativePath), "/") + "/"
+	if !group.engine.hasRoute("GET", group.prefix+root) {
+		group.GET(root, handler)
+	}
+}
+
+type staticHandler struct {
+	StaticConfig
+	etags        sync.Map          // file name to ETag, for files without modification time
+	fingerprints map[string]string // fingerprinted name to file name
+}
+
+func (group *RouterGroup) createStaticHandler(absolutePath string, config StaticConfig) HandlerFunc {
+	h := &staticHandler{StaticConfig: config}
+	if config.Fingerprint {
+		if err := h.fingerprint(group.engine, absolutePath); err != nil {
+			panic(err)
+		}
+	}
+	return h.serve
+}
+
+// fingerprint hashes every file of Root and registers its fingerprinted URL as an asset
+func (h *staticHandler) fingerprint(engine *Engine, absolutePath string) error {
+	h.fingerprints = make(map[string]string)
+	if engine.assets == nil {
+		engine.assets = make(map[string]string)
+	}
+	return fs.WalkDir(h.Root, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if h.Precompressed && strings.HasSuffix(name, ".gz") {
+			return nil
+		}
+		f, err := h.Root.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return err
+		}
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(hash.Sum(nil)[:8]) + ext
+		h.fingerprints[hashed] = name
+		url := path.Join(absolutePath, hashed)
+		for _, key := range []string{name, path.Join(absolutePath, name)} {
+			if other, ok := engine.assets[key]; ok && other != url {
+				return fmt.Errorf("going: asset %q is already served as %s", key, other)
+			}
+			engine.assets[key] = url
+		}
+		return nil
+	})
+}
+
+// assetURL returns the fingerprinted URL of a file served by a static route with
+// Fingerprint enabled, name is relative to its root or the URL path of the file
+func (engine *Engine) assetURL(name string) (string, error) {
+	if url, ok := engine.assets[name]; ok {
+		return url, nil
+	}
+	return "", fmt.Errorf("going: unknown asset %q", name)
+}
+
+func (h *staticHandler) serve(c *Context) {
+	name := strings.TrimPrefix(path.Clean("/"+c.Param("filepath")), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		notFound(c)
+		return
+	}
+	if file, ok := h.fingerprints[name]; ok {
+		if info, err := fs.Stat(h.Root, file); err == nil {
+			c.Writer.Header().Set("Cache-Control", immutableCacheControl)
+			h.serveFile(c, file, info)
+			return
+		}
+	}
+	info, err := fs.Stat(h.Root, name)
+	if err != nil {
+		h.fallback(c)
+		return
+	}
+	if !info.IsDir() {
+		h.serveFile(c, name, info)
+		return
+	}
+
+	// like http.FileServer, directories are only served with a trailing slash
+	// so that relative links in their index resolve
+	if urlPath := c.Req.URL.Path; !strings.HasSuffix(urlPath, "/") {
+		location := path.Base(urlPath) + "/"
+		if c.Req.URL.RawQuery != "" {
+			location += "?" + c.Req.URL.RawQuery
+		}
+		c.SetHeader("Location", location)
+		c.Status(http.StatusMovedPermanently)
+		return
+	}
+	index := path.Join(name, h.Index)
+	if info, err := fs.Stat(h.Root, index); err == nil && !info.IsDir() {
+		h.serveFile(c, index, info)
+		return
+	}
+	if h.Browse {
+		h.list(c, name)
+		return
+	}
+	h.fallback(c)
+}
+
+// fallback answers requests matching no file, with the root index in SPA mode
+func (h *staticHandler) fallback(c *Context) {
+	if h.SPA {
+		if info, err := fs.Stat(h.Root, h.Index); err == nil && !info.IsDir() {
+			h.serveFile(c, h.Index, info)
+			return
+		}
+	}
+	notFound(c)
+}
+
+func (h *staticHandler) serveFile(c *Context, name string, info fs.FileInfo) {
+	header := c.Writer.Header()
+	ext := strings.ToLower(path.Ext(name))
+	// a fingerprinted name comes with its Cache-Control already set
+	if header.Get("Cache-Control") == "" {
+		if value, ok := h.CacheControl[ext]; ok {
+			header.Set("Cache-Control", value)
+		} else if value, ok := h.CacheControl["*"]; ok {
+			header.Set("Cache-Control", value)
+		}
+	}
+
+	served := name
+	if h.Precompressed {
+		addVary(header, "Accept-Encoding")
+		if acceptsGzip(c.Req) {
+			if gz, err := fs.Stat(h.Root, name+".gz"); err == nil && !gz.IsDir() {
+				// the content type comes from the original name, sniffing would see gzip
+				contentType := mime.TypeByExtension(ext)
+				if contentType == "" {
+					contentType = "application/octet-stream"
+				}
+				header.Set("Content-Type", contentType)
+				header.Set("Content-Encoding", "gzip")
+				served, info = name+".gz", gz
+			}
+		}
+	}
+
+	content, err := h.open(served)
+	if err != nil {
+		c.Fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+	header.Set("ETag", h.etag(served, info, content))
+	// ServeContent takes care of Range, Last-Modified and the conditional headers
+	http.ServeContent(c.Writer, c.Req, name, info.ModTime(), content)
+}
+
+// open returns the content of a file, read into memory when it can not seek
+func (h *staticHandler) open(name string) (io.ReadSeeker, error) {
+	f, err := h.Root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// etag derives a weak ETag from size and modification time, or hashes the
+// content of files without one, like those of an embed.FS, once
+func (h *staticHandler) etag(name string, info fs.FileInfo, content io.ReadSeeker) string {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+	}
+	if etag, ok := h.etags.Load(name); ok {
+		return etag.(string)
+	}
+	hash := sha256.New()
+	io.Copy(hash, content)
+	content.Seek(0, io.SeekStart)
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etag)
+	return etag
+}
+
+var dirListTemplate = template.Must(template.New("").Parse(
+	`<!doctype html><meta name="viewport" content="width=device-width"><pre>
+{{range .}}<a href="{{.URL}}">{{.Name}}</a>
+{{end}}</pre>
+`))
+
+type dirEntry struct {
+	Name string
+	URL  string
+}
+
+// list writes a listing of the directory name
+func (h *staticHandler) list(c *Context, name string) {
+	entries, err := fs.ReadDir(h.Root, name)
+	if err != nil {
+		c.Fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	list := make([]dirEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		// a path only URL keeps names with a colon from reading as a scheme
+		list = append(list, dirEntry{Name: name, URL: (&url.URL{Path: name}).String()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := dirListTemplate.Execute(buf, list); err != nil {
+		c.Fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	c.SetHeader("Content-Type", "text/html; charset=utf-8")
+	c.Data(http.StatusOK, buf.Bytes())
+}
+
+func acceptsGzip(r *http.Request) bool {
+	qualities := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	q, ok := qualities["gzip"]
+	if !ok {
+		q = qualities["*"]
+	}
+	return q > 0
+}
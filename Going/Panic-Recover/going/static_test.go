@@ -0,0 +1,134 @@
+package going
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticWithConfig(t *testing.T) {
+	root := fstest.MapFS{
+		"index.html":      &fstest.MapFile{Data: []byte("app")},
+		"css/app.css":     &fstest.MapFile{Data: []byte("body{}")},
+		"css/app.css.gz":  &fstest.MapFile{Data: []byte("gzipped")},
+		"docs/guide.html": &fstest.MapFile{Data: []byte("guide")},
+	}
+	r := New()
+	r.StaticWithConfig("/app", StaticConfig{
+		Root:          root,
+		SPA:           true,
+		CacheControl:  map[string]string{".css": "max-age=3600", "*": "no-cache"},
+		Precompressed: true,
+	})
+	r.StaticWithConfig("/files", StaticConfig{Root: root, Browse: true})
+	r.StaticWithConfig("/private", StaticConfig{Root: root})
+	serve := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/app/css/app.css")
+	if w.Body.String() != "body{}" || w.Header().Get("Cache-Control") != "max-age=3600" {
+		t.Fatalf("unexpected css response %q %q", w.Body.String(), w.Header().Get("Cache-Control"))
+	}
+	etag := w.Header().Get("ETag")
+	if w := serve("/app/css/app.css", "If-None-Match", etag); etag == "" || w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for etag %q, got %d", etag, w.Code)
+	}
+
+	w = serve("/app/css/app.css", "Accept-Encoding", "gzip")
+	if w.Body.String() != "gzipped" || w.Header().Get("Content-Encoding") != "gzip" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+		t.Fatalf("expected the precompressed file, got %q %v", w.Body.String(), w.Header())
+	}
+
+	if w := serve("/app/users/42"); w.Body.String() != "app" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("expected the SPA index, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve("/app"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/app/" {
+		t.Fatalf("expected a redirect to the directory, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := serve("/app/"); w.Body.String() != "app" {
+		t.Fatalf("expected the index file, got %d %q", w.Code, w.Body.String())
+	}
+
+	if w := serve("/files/docs/"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<a href="guide.html">guide.html</a>`) {
+		t.Fatalf("expected a listing, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve("/private/docs/"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected listings to be disabled, got %d", w.Code)
+	}
+}
+
+func TestStaticFingerprint(t *testing.T) {
+	r := New()
+	r.StaticWithConfig("/static", StaticConfig{
+		Root:        fstest.MapFS{"css/app.css": &fstest.MapFile{Data: []byte("body{}")}},
+		Fingerprint: true,
+	})
+	r.LoadHTMLFS(fstest.MapFS{
+		"page.tmpl": &fstest.MapFile{Data: []byte(`<link href="{{asset "css/app.css"}}">`)},
+	}, "*.tmpl")
+	r.GET("/", func(c *Context) {
+		c.HTML(http.StatusOK, "page.tmpl", nil)
+	})
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	url, err := r.assetURL("css/app.css")
+	if err != nil || !strings.HasPrefix(url, "/static/css/app.") || !strings.HasSuffix(url, ".css") {
+		t.Fatalf("unexpected asset url %q %v", url, err)
+	}
+	if w := serve("/"); w.Body.String() != `<link href="`+url+`">` {
+		t.Fatalf("unexpected page %q", w.Body.String())
+	}
+	w := serve(url)
+	if w.Body.String() != "body{}" || w.Header().Get("Cache-Control") != immutableCacheControl ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+		t.Fatalf("unexpected fingerprinted response %q %v", w.Body.String(), w.Header())
+	}
+	if w := serve("/static/css/app.css"); w.Body.String() != "body{}" || w.Header().Get("Cache-Control") != "" {
+		t.Fatalf("the original name should still be served without caching, got %q %v", w.Body.String(), w.Header())
+	}
+}
+
+func TestStaticFingerprintConflict(t *testing.T) {
+	r := New()
+	r.StaticWithConfig("/a", StaticConfig{
+		Root:        fstest.MapFS{"css/app.css": &fstest.MapFile{Data: []byte("a{}")}},
+		Fingerprint: true,
+	})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an asset name served by two roots")
+		}
+	}()
+	r.StaticWithConfig("/b", StaticConfig{
+		Root:        fstest.MapFS{"css/app.css": &fstest.MapFile{Data: []byte("b{}")}},
+		Fingerprint: true,
+	})
+}
+
+func TestStaticKeepsRootRoute(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) { c.String(http.StatusOK, "home") })
+	r.StaticFS("/", fstest.MapFS{"app.js": &fstest.MapFile{Data: []byte("js")}})
+
+	for target, body := range map[string]string{"/": "home", "/app.js": "js"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != http.StatusOK || w.Body.String() != body {
+			t.Fatalf("%s: expected %q, got %d %q", target, body, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package going
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutConfig defines the config for the Timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration
+	// StatusCode is sent when the deadline expires, 503 by default
+	StatusCode int
+	// Response writes the timeout response instead of the default Fail
+	Response HandlerFunc
+}
+
+// Timeout returns a middleware that gives the remaining handlers d to respond
+func Timeout(d time.Duration) HandlerFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig returns a Timeout middleware with config. The remaining handlers run
+// on a copy of the Context whose output is buffered, so once the deadline expired the
+// timeout response is written exactly once and later writes of the handler are dropped.
+// Streaming with Flush is not possible below this middleware.
+func TimeoutWithConfig(config TimeoutConfig) HandlerFunc {
+	if config.Timeout <= 0 {
+		panic("going: timeout must be positive")
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	if config.Response == nil {
+		config.Response = func(c *Context) {
+			c.Fail(config.StatusCode, http.StatusText(config.StatusCode))
+		}
+	}
+	return func(c *Context) {
+		ctx, cancel := context.WithTimeout(c.Req.Context(), config.Timeout)
+		defer cancel()
+
+		tw := newTimeoutWriter(c.Writer.Header())
+		cp := c.fork(tw, c.Req.WithContext(ctx))
+		c.Abort()
+
+		done := make(chan struct{})
+		panicChan := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+			}()
+			cp.Next()
+			close(done)
+		}()
+
+		select {
+		case p := <-panicChan:
+			// re-panic on the serving goroutine so Recovery can handle it
+			panic(p)
+		case <-done:
+			c.join(cp)
+			tw.copyTo(c.Writer)
+		case <-ctx.Done():
+			tw.timeout()
+			if ctx.Err() == context.DeadlineExceeded {
+				config.Response(c)
+			}
+		}
+	}
+}
+
+// fork returns a Context that continues the handler chain with its own writer and request.
+// Keys and Errors are copied so both contexts can set them concurrently, but the copy
+// is shallow: pointer values in Keys, like the session, stay shared with c.
+func (c *Context) fork(w ResponseWriter, req *http.Request) *Context {
+	cp := &Context{
+		Writer:     w,
+		Req:        req,
+		Path:       c.Path,
+		Method:     c.Method,
+		Params:     c.Params,
+		StatusCode: c.StatusCode,
+		handlers:   c.handlers,
+		index:      c.index,
+		Errors:     append(errorMsgs(nil), c.Errors...),
+		sameSite:   c.sameSite,
+		engine:     c.engine,
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Keys != nil {
+		cp.Keys = make(map[string]any, len(c.Keys))
+		for k, v := range c.Keys {
+			cp.Keys[k] = v
+		}
+	}
+	for name, fn := range c.templateFuncs {
+		cp.bindTemplateFunc(name, fn)
+	}
+	return cp
+}
+
+// join takes over the state a finished fork produced
+func (c *Context) join(cp *Context) {
+	c.mu.Lock()
+	c.Keys = cp.Keys
+	c.mu.Unlock()
+	c.Errors = cp.Errors
+	c.StatusCode = cp.StatusCode
+}
+
+// timeoutWriter buffers a response until the handler finished in time
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter(header http.Header) *timeoutWriter {
+	return &timeoutWriter{header: header.Clone(), status: defaultStatus}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if code > 0 && !tw.timedOut && !tw.wroteHeader {
+		tw.status = code
+	}
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.buf.Write(data)
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.wroteHeader = true
+}
+
+// Flush is a no-op, the response is only sent once the handler returned
+func (tw *timeoutWriter) Flush() {}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.status
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.wroteHeader {
+		return noWritten
+	}
+	return tw.buf.Len()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.wroteHeader
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.timedOut = true
+}
+
+// copyTo replays the buffered response, a response that was never written keeps
+// its status pending so the engine's error handler can still answer
+func (tw *timeoutWriter) copyTo(w ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	dst := w.Header()
+	for key := range dst {
+		if _, ok := tw.header[key]; !ok {
+			delete(dst, key)
+		}
+	}
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+	w.WriteHeader(tw.status)
+	if tw.wroteHeader {
+		w.WriteHeaderNow()
+		w.Write(tw.buf.Bytes())
+	}
+}
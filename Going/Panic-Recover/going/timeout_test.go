@@ -0,0 +1,100 @@
+package going
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var user string
+	r := New()
+	r.Use(RequestID(), func(c *Context) {
+		c.Next()
+		user = c.GetString("user")
+	}, Timeout(50*time.Millisecond))
+	r.GET("/fast", func(c *Context) {
+		c.Set("user", "alice")
+		c.SetHeader("X-Handler", "fast")
+		c.String(http.StatusCreated, "done")
+	})
+	r.GET("/slow", func(c *Context) {
+		<-c.Req.Context().Done()
+		<-release
+		// too late, this must not reach the client
+		c.String(http.StatusOK, "late")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Handler") != "fast" {
+		t.Fatalf("fast handler should respond normally, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if w.Header().Get("X-Request-ID") == "" {
+		t.Fatal("headers set before Timeout should be kept")
+	}
+	if user != "alice" {
+		t.Fatalf("keys set below Timeout should be joined back, got %q", user)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("slow handler should time out with 503, got %d", w.Code)
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	r := New()
+	r.Use(RecoveryWithWriter(nil), Timeout(time.Second))
+	r.GET("/panic", func(c *Context) {
+		panic("oops")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("panics below Timeout should reach Recovery, got %d", w.Code)
+	}
+}
+
+func TestContextForkAndJoin(t *testing.T) {
+	engine := New()
+	handler := func(c *Context) {}
+	c := &Context{
+		Path:       "/path",
+		Method:     "POST",
+		Params:     map[string]string{"id": "1"},
+		StatusCode: http.StatusCreated,
+		handlers:   []HandlerFunc{handler, handler},
+		index:      1,
+		Keys:       map[string]any{"user": "alice"},
+		sameSite:   http.SameSiteStrictMode,
+		engine:     engine,
+	}
+	c.Error(errors.New("first"))
+	c.bindTemplateFunc("upper", strings.ToUpper)
+
+	cp := c.fork(nil, nil)
+	if cp.Path != "/path" || cp.Method != "POST" || cp.Params["id"] != "1" || cp.StatusCode != http.StatusCreated ||
+		len(cp.handlers) != 2 || cp.index != 1 || cp.sameSite != http.SameSiteStrictMode || cp.engine != engine ||
+		cp.Keys["user"] != "alice" || len(cp.Errors) != 1 || cp.templateFuncs["upper"] == nil {
+		t.Fatalf("fork should copy the context, got %+v", cp)
+	}
+
+	cp.Set("role", "admin")
+	cp.Error(errors.New("second"))
+	cp.StatusCode = http.StatusAccepted
+	if _, ok := c.Get("role"); ok || len(c.Errors) != 1 {
+		t.Fatal("the fork should not change the keys and errors of the original")
+	}
+	c.join(cp)
+	if c.GetString("role") != "admin" || len(c.Errors) != 2 || c.StatusCode != http.StatusAccepted {
+		t.Fatalf("join should copy the keys, errors and status back, got %v %v %d", c.Keys, c.Errors, c.StatusCode)
+	}
+}
@@ -52,6 +52,38 @@ func (n *node) search(parts []string, height int) *node {
 	return nil
 }
 
+// searchFold is search with static parts compared case-insensitively,
+// it also returns the matched parts spelled as registered
+func (n *node) searchFold(parts []string, height int, fixed []string) (*node, []string) {
+	if len(parts) == height || strings.HasPrefix(n.part, "*") {
+		if n.pattern == "" {
+			return nil, nil
+		}
+		if strings.HasPrefix(n.part, "*") {
+			fixed = append(fixed, parts[height:]...)
+		}
+		return n, fixed
+	}
+
+	part := parts[height]
+	for _, child := range n.children {
+		if !child.isWild && !strings.EqualFold(child.part, part) {
+			continue
+		}
+		spelled := child.part
+		if child.isWild {
+			spelled = part
+		}
+		// fixed is copied so siblings do not share the appended part
+		next := append(fixed[:len(fixed):len(fixed)], spelled)
+		if result, path := child.searchFold(parts, height+1, next); result != nil {
+			return result, path
+		}
+	}
+
+	return nil, nil
+}
+
 func (n *node) travel(list *([]*node)) {
 	if n.pattern != "" {
 		*list = append(*list, n)